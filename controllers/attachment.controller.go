@@ -0,0 +1,132 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentController struct{}
+
+// HandleGet handles GET requests for listing the attachments of a question
+func (c *AttachmentController) HandleGet(ctx *gin.Context) {
+	attachments, err := service.GetAttachments(ctx.Param("id"))
+	if err != nil {
+		writeAttachmentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+// HandleGetByName handles GET requests for the content of an attachment. The route is public so that descriptions can
+// reference attachments from img tags, which don't send the Authorization header.
+func (c *AttachmentController) HandleGetByName(ctx *gin.Context) {
+	attachment, content, err := service.OpenAttachment(ctx.Param("id"), ctx.Param("name"))
+	if err != nil {
+		writeAttachmentError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    disposition + "; filename=" + strconv.Quote(attachment.Name),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "public, max-age=3600",
+	})
+}
+
+// HandlePost handles POST requests for uploading an attachment to a question, from a multipart file field and an optional name
+// field that defaults to the name of the uploaded file
+func (c *AttachmentController) HandlePost(ctx *gin.Context) {
+	id := ctx.Param("id")
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing attachment file"})
+		return
+	}
+	if header.Size > service.AttachmentSizeLimit {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	name := ctx.PostForm("name")
+	if name == "" {
+		name = header.Filename
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	attachment, err := service.UploadAttachment(id, currentUser(ctx).ID, name, content)
+	if err != nil {
+		writeAttachmentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, attachment)
+}
+
+// HandleDelete handles DELETE requests for removing an attachment from a question
+func (c *AttachmentController) HandleDelete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	if err := service.DeleteAttachment(id, ctx.Param("name")); err != nil {
+		writeAttachmentError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// writeAttachmentError writes the error response of an attachment request
+func writeAttachmentError(ctx *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidAttachmentName, service.ErrUnsupportedAttachment:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrAttachmentTooLarge:
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case service.ErrAttachmentExists:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrQuestionNotFound, service.ErrAttachmentNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// PublicRoutes lists the routes of the attachment controller that do not require authentication
+func (c *AttachmentController) PublicRoutes() []string {
+	return []string{"GET /questions/:id/attachments/:name"}
+}
+
+// RegisterHandlers registers all routes for the attachment controller
+func (c *AttachmentController) RegisterHandlers(router *gin.Engine) {
+	manage := RequireRole(models.RoleAuthor, models.RoleAdmin)
+
+	router.GET("/questions/:id/attachments", c.HandleGet)
+	router.GET("/questions/:id/attachments/:name", c.HandleGetByName)
+	router.POST("/questions/:id/attachments", manage, c.HandlePost)
+	router.DELETE("/questions/:id/attachments/:name", manage, c.HandleDelete)
+}
@@ -0,0 +1,70 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const userKey = "user"
+
+// AuthMiddleware requires a valid "Authorization: Bearer <token>" header on every route except the given public ones,
+// and stores the authenticated user on the context for the handlers. Requests matching no route are let through so that
+// gin answers them with 404 Not Found.
+func AuthMiddleware(publicRoutes ...string) gin.HandlerFunc {
+	public := make(map[string]bool, len(publicRoutes))
+	for _, route := range publicRoutes {
+		public[route] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method == http.MethodOptions || ctx.FullPath() == "" || public[ctx.Request.Method+" "+ctx.FullPath()] {
+			ctx.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization token"})
+			return
+		}
+
+		user, err := service.Authenticate(token)
+		if err != nil {
+			if err == service.ErrInvalidToken {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Set(userKey, user)
+		ctx.Next()
+	}
+}
+
+// currentUser returns the user authenticated by AuthMiddleware, or nil on public routes
+func currentUser(ctx *gin.Context) *models.User {
+	value, _ := ctx.Get(userKey)
+	user, _ := value.(*models.User)
+	return user
+}
+
+// RequireRole rejects requests from users whose role is not one of the given roles with 403 Forbidden.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := currentUser(ctx)
+		for _, role := range roles {
+			if user != nil && user.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Requires one of the roles: " + strings.Join(roles, ", ")})
+	}
+}
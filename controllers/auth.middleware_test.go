@@ -0,0 +1,38 @@
+package questioncontroller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthMiddlewareWithoutToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddleware("POST /users/login"))
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	router.POST("/users/login", ok)
+	router.GET("/questions/:id", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"public route", http.MethodPost, "/users/login", http.StatusOK},
+		{"protected route", http.MethodGet, "/questions/1", http.StatusUnauthorized},
+		{"unknown route", http.MethodGet, "/unknown", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+			if recorder.Code != test.status {
+				t.Errorf("%s %s status = %d, want %d", test.method, test.path, recorder.Code, test.status)
+			}
+		})
+	}
+}
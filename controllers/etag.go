@@ -0,0 +1,36 @@
+package questioncontroller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the version of a question as its entity tag
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseIfMatch reads the question version expected by the If-Match header required by the requests changing a question.
+// A "*" matches any version and returns 0. It writes the error response and returns false when the request must not continue.
+func parseIfMatch(ctx *gin.Context) (int, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": "Missing If-Match header with the ETag of the question"})
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err == nil {
+		if version, err := strconv.Atoi(unquoted); err == nil && version > 0 {
+			return version, true
+		}
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		header  string
+		version int
+		ok      bool
+		status  int
+	}{
+		{"version", `"3"`, 3, true, http.StatusOK},
+		{"spaces", ` "12" `, 12, true, http.StatusOK},
+		{"any version", "*", 0, true, http.StatusOK},
+		{"missing", "", 0, false, http.StatusPreconditionRequired},
+		{"unquoted", "3", 0, false, http.StatusBadRequest},
+		{"weak", `W/"3"`, 0, false, http.StatusBadRequest},
+		{"not a number", `"abc"`, 0, false, http.StatusBadRequest},
+		{"zero", `"0"`, 0, false, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPut, "/questions", nil)
+			if test.header != "" {
+				ctx.Request.Header.Set("If-Match", test.header)
+			}
+
+			version, ok := parseIfMatch(ctx)
+			if version != test.version || ok != test.ok || recorder.Code != test.status {
+				t.Errorf("parseIfMatch() = %d, %v with status %d, want %d, %v with status %d",
+					version, ok, recorder.Code, test.version, test.ok, test.status)
+			}
+		})
+	}
+}
+
+func TestWriteQuestionUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		etag   string
+	}{
+		{"updated", nil, http.StatusOK, `"4"`},
+		{"conflict", service.ErrVersionConflict, http.StatusPreconditionFailed, ""},
+		{"not found", service.ErrQuestionNotFound, http.StatusNotFound, ""},
+		{"invalid", service.ErrInvalidQuestion, http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			var question *models.Question
+			if test.err == nil {
+				question = &models.Question{Level: models.LevelEasy, Version: 4}
+			}
+
+			writeQuestionUpdate(ctx, question, test.err)
+			if recorder.Code != test.status || recorder.Header().Get("ETag") != test.etag {
+				t.Errorf("writeQuestionUpdate() status = %d, ETag = %q, want %d, %q",
+					recorder.Code, recorder.Header().Get("ETag"), test.status, test.etag)
+			}
+		})
+	}
+}
+
+func TestETagRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	setETag(ctx, 7)
+
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/questions/id", nil)
+	ctx.Request.Header.Set("If-Match", recorder.Header().Get("ETag"))
+	if version, ok := parseIfMatch(ctx); version != 7 || !ok {
+		t.Errorf("parseIfMatch() of the ETag = %d, %v, want 7, true", version, ok)
+	}
+}
@@ -0,0 +1,135 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListController struct{}
+
+// HandleGet handles GET requests for listing the public problem lists and the current user's lists, or only the latter with mine=true
+func (c *ListController) HandleGet(ctx *gin.Context) {
+	lists, err := service.GetLists(currentUser(ctx).ID, ctx.Query("mine") == "true")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, lists)
+}
+
+// HandleGetByID handles GET requests for a problem list with its questions and the current user's progress on them
+func (c *ListController) HandleGetByID(ctx *gin.Context) {
+	list, err := service.GetListByID(ctx.Param("id"))
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+	// Private lists of other users are reported as missing rather than forbidden so that their IDs don't leak.
+	if !service.CanViewList(currentUser(ctx), list) {
+		writeListError(ctx, service.ErrListNotFound)
+		return
+	}
+
+	detail, err := service.GetListDetail(currentUser(ctx), list)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, detail)
+}
+
+// HandlePost handles POST requests for creating a problem list owned by the current user
+func (c *ListController) HandlePost(ctx *gin.Context) {
+	var body models.ProblemList
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	list, err := service.CreateList(currentUser(ctx).ID, body)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, list)
+}
+
+// HandlePut handles PUT requests for replacing the name, description, visibility and questions of a problem list
+func (c *ListController) HandlePut(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !authorizeManageList(ctx, id) {
+		return
+	}
+
+	var body models.ProblemList
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	list, err := service.UpdateList(id, body)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// HandleDelete handles DELETE requests for deleting a problem list
+func (c *ListController) HandleDelete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !authorizeManageList(ctx, id) {
+		return
+	}
+
+	if err := service.DeleteList(id); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// authorizeManageList loads a problem list and checks that the current user may manage it.
+// It writes the error response and returns false when the request must not continue.
+func authorizeManageList(ctx *gin.Context, id string) bool {
+	list, err := service.GetListByID(id)
+	if err != nil {
+		writeListError(ctx, err)
+		return false
+	}
+	user := currentUser(ctx)
+	if !service.CanViewList(user, list) {
+		writeListError(ctx, service.ErrListNotFound)
+		return false
+	}
+	if !service.CanManageList(user, list) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the list owner or an admin can modify this list"})
+		return false
+	}
+	return true
+}
+
+// writeListError writes the error response of a problem list request
+func writeListError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidList):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err == service.ErrListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// RegisterHandlers registers all routes for the list controller
+func (c *ListController) RegisterHandlers(router *gin.Engine) {
+	router.GET("/lists", c.HandleGet)
+	router.GET("/lists/:id", c.HandleGetByID)
+	router.POST("/lists", c.HandlePost)
+	router.PUT("/lists/:id", c.HandlePut)
+	router.DELETE("/lists/:id", c.HandleDelete)
+}
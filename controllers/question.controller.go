@@ -1,22 +1,123 @@
 package questioncontroller
 
 import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
 	"net/http"
-	"LeetCode-server/services"
-	"LeetCode-server/models"
+	"sigs.k8s.io/yaml"
+	"strconv"
 )
 
 type QuestionController struct{}
 
-// HandleGet handles GET requests for retrieving questions
+// HandleGet handles GET requests for listing question summaries page by page,
+// filtered by level, tag and language and sorted by title, level or creation date
 func (c *QuestionController) HandleGet(ctx *gin.Context) {
-	questions, err := service.GetAllQuestions()
+	filter, ok := parseQuestionFilter(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := service.ListQuestions(filter)
 	if err != nil {
+		if err == service.ErrInvalidSort {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, questions)
+
+	summaries := make([]*models.QuestionSummary, len(page.Questions))
+	for i := range page.Questions {
+		summaries[i] = &page.Questions[i]
+	}
+	if err := setQuestionStatuses(ctx, summaries); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// HandleSearch handles GET requests for searching questions by title and description text, ranked by relevance
+func (c *QuestionController) HandleSearch(ctx *gin.Context) {
+	filter, ok := parseQuestionFilter(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := service.SearchQuestions(ctx.Query("q"), filter)
+	if err != nil {
+		if err == service.ErrEmptySearch {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries := make([]*models.QuestionSummary, len(page.Results))
+	for i := range page.Results {
+		summaries[i] = &page.Results[i].QuestionSummary
+	}
+	if err := setQuestionStatuses(ctx, summaries); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// setQuestionStatuses annotates question summaries with whether the current user solved or attempted them
+func setQuestionStatuses(ctx *gin.Context, summaries []*models.QuestionSummary) error {
+	ids := make([]primitive.ObjectID, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+	}
+	statuses, err := service.GetQuestionStatuses(currentUser(ctx).ID, ids)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		summary.Status = statuses[summary.ID]
+	}
+	return nil
+}
+
+// parseQuestionFilter reads the filtering, sorting and pagination query parameters of question listings.
+// It writes the error response and returns false when a parameter is invalid.
+func parseQuestionFilter(ctx *gin.Context) (models.QuestionFilter, bool) {
+	filter := models.QuestionFilter{
+		Tag:      ctx.Query("tag"),
+		Language: ctx.Query("language"),
+		Sort:     ctx.DefaultQuery("sort", "created"),
+	}
+
+	var err error
+	if filter.Page, err = strconv.Atoi(ctx.DefaultQuery("page", "1")); err != nil || filter.Page < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive number"})
+		return filter, false
+	}
+	if filter.PageSize, err = strconv.Atoi(ctx.DefaultQuery("pageSize", "20")); err != nil || filter.PageSize < 1 || filter.PageSize > 100 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "pageSize must be a number between 1 and 100"})
+		return filter, false
+	}
+	if level := ctx.Query("level"); level != "" {
+		if filter.Level, err = strconv.Atoi(level); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "level must be a number"})
+			return filter, false
+		}
+	}
+	if difficulty := ctx.Query("difficulty"); difficulty != "" {
+		if filter.Level, err = service.ParseDifficulty(difficulty); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return filter, false
+		}
+	}
+	return filter, true
 }
 
 // HandleGetByID handles GET requests for retrieving a question by ID
@@ -29,15 +130,23 @@ func (c *QuestionController) HandleGetByID(ctx *gin.Context) {
 
 	question, err := service.GetQuestionByID(id)
 	if err != nil {
+		if err == service.ErrQuestionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if question == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+	if question.DescriptionHTML, err = service.RenderDescription(*question); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	service.RedactQuestion(currentUser(ctx), question)
+	question.Difficulty = models.Difficulties[question.Level]
+	question.Stats = service.GetQuestionStats(question)
 
+	setETag(ctx, question.Version)
 	ctx.JSON(http.StatusOK, question)
 }
 
@@ -49,9 +158,9 @@ func (c *QuestionController) HandlePost(ctx *gin.Context) {
 		return
 	}
 
-	createdQuestion, err := service.CreateQuestion(newQuestion.Title, newQuestion.Description, newQuestion.Level, newQuestion.Tests, newQuestion.InputTypes, newQuestion.OutputType)
+	createdQuestion, err := service.CreateQuestion(currentUser(ctx).ID, newQuestion)
 	if err != nil {
-		if err.Error() == "Question must contain title & description & level & at least one test" {
+		if err == service.ErrInvalidQuestion || err == service.ErrInvalidDifficulty || err == service.ErrUnsupportedLanguage || errors.Is(err, service.ErrUnknownTag) || errors.Is(err, service.ErrInvalidExample) {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -70,19 +179,164 @@ func (c *QuestionController) HandlePut(ctx *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
 	var updatedQuestion models.Question
 	if err := ctx.ShouldBindJSON(&updatedQuestion); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
-	updatedQuestionResult, err := service.UpdateQuestion(id, updatedQuestion.Title, updatedQuestion.Description, updatedQuestion.Level, updatedQuestion.Tests, updatedQuestion.InputTypes, updatedQuestion.OutputType)
+	updatedQuestionResult, err := service.UpdateQuestion(id, currentUser(ctx).ID, expectedVersion, updatedQuestion)
+	writeQuestionUpdate(ctx, updatedQuestionResult, err)
+}
+
+// HandlePatch handles PATCH requests for partially updating a question with a JSON Merge Patch
+func (c *QuestionController) HandlePatch(ctx *gin.Context) {
+	id := ctx.Param("id")
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	patch, err := ctx.GetRawData()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	question, err := service.PatchQuestion(id, currentUser(ctx).ID, expectedVersion, patch)
+	writeQuestionUpdate(ctx, question, err)
+}
+
+// HandlePostTest handles POST requests for inserting a test case at an index of a question's tests
+func (c *QuestionController) HandlePostTest(ctx *gin.Context) {
+	c.handleTestChange(ctx, true, func(id string, version, index int, test models.Test) (*models.Question, error) {
+		return service.InsertTest(id, currentUser(ctx).ID, version, index, test)
+	})
+}
+
+// HandlePutTest handles PUT requests for replacing the test case at an index of a question's tests
+func (c *QuestionController) HandlePutTest(ctx *gin.Context) {
+	c.handleTestChange(ctx, true, func(id string, version, index int, test models.Test) (*models.Question, error) {
+		return service.ReplaceTest(id, currentUser(ctx).ID, version, index, test)
+	})
+}
+
+// HandleDeleteTest handles DELETE requests for removing the test case at an index of a question's tests
+func (c *QuestionController) HandleDeleteTest(ctx *gin.Context) {
+	c.handleTestChange(ctx, false, func(id string, version, index int, _ models.Test) (*models.Question, error) {
+		return service.DeleteTest(id, currentUser(ctx).ID, version, index)
+	})
+}
+
+// handleTestChange parses the question ID, test index, If-Match header and, when withBody is set, the test case
+// of a test sub-resource request, then applies the change and writes the updated question.
+func (c *QuestionController) handleTestChange(ctx *gin.Context, withBody bool, change func(string, int, int, models.Test) (*models.Question, error)) {
+	id := ctx.Param("id")
+	index, err := strconv.Atoi(ctx.Param("index"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "index must be a number"})
+		return
+	}
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	var test models.Test
+	if withBody {
+		if err := ctx.ShouldBindJSON(&test); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	question, err := change(id, expectedVersion, index, test)
+	writeQuestionUpdate(ctx, question, err)
+}
+
+// HandleUploadTests handles POST requests for adding the test cases of a zip archive of N.in and N.out files to a question,
+// after its existing tests or, with mode=replace, in place of them
+func (c *QuestionController) HandleUploadTests(ctx *gin.Context) {
+	id := ctx.Param("id")
+	mode := ctx.DefaultQuery("mode", "append")
+	if mode != "append" && mode != "replace" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "mode must be either append or replace"})
+		return
+	}
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing test archive file"})
+		return
+	}
+	if header.Size > service.TestArchiveLimit {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrTestArchiveTooLarge.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	defer file.Close()
+	archive, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	question, err := service.UploadTests(id, currentUser(ctx).ID, expectedVersion, archive, mode == "replace")
+	writeQuestionUpdate(ctx, question, err)
+}
+
+// writeQuestionUpdate writes the response of a request that created a new version of a question
+func writeQuestionUpdate(ctx *gin.Context, question *models.Question, err error) {
+	if err != nil {
+		switch {
+		case err == service.ErrInvalidQuestion || err == service.ErrInvalidDifficulty || err == service.ErrUnsupportedLanguage || errors.Is(err, service.ErrInvalidPatch) || errors.Is(err, service.ErrUnknownTag) || errors.Is(err, service.ErrInvalidExample):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err == service.ErrQuestionNotFound || err == service.ErrVersionNotFound || err == service.ErrTestNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidTestArchive):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err == service.ErrTestArchiveTooLarge:
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case err == service.ErrVersionConflict:
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, updatedQuestionResult)
+	question.Difficulty = models.Difficulties[question.Level]
+	setETag(ctx, question.Version)
+	ctx.JSON(http.StatusOK, question)
 }
 
 // HandleDelete handles DELETE requests for deleting a question
@@ -93,19 +347,124 @@ func (c *QuestionController) HandleDelete(ctx *gin.Context) {
 		return
 	}
 
-	deleteResult, err := service.DeleteQuestion(id)
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	err := service.DeleteQuestion(id, currentUser(ctx).ID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error":"Question not exist"})
+		if err == service.ErrQuestionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, deleteResult)
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleGetTrash handles GET requests for listing deleted questions: every deleted question for admins, and their own for authors
+func (c *QuestionController) HandleGetTrash(ctx *gin.Context) {
+	user := currentUser(ctx)
+	authorID := user.ID
+	if user.Role == models.RoleAdmin {
+		authorID = primitive.NilObjectID
+	}
+
+	questions, err := service.GetDeletedQuestions(authorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, questions)
+}
+
+// HandleRestore handles POST requests for taking a question out of the trash
+func (c *QuestionController) HandleRestore(ctx *gin.Context) {
+	id := ctx.Param("id")
+	question, err := service.GetDeletedQuestionByID(id)
+	if err != nil {
+		if err == service.ErrQuestionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !service.CanManageQuestion(currentUser(ctx), question) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the question author or an admin can modify this question"})
+		return
+	}
+
+	question, err = service.RestoreQuestion(id)
+	writeQuestionUpdate(ctx, question, err)
+}
+
+// HandleExport handles GET requests for exporting every question as a portable bundle, in JSON or, with format=yaml, in YAML
+func (c *QuestionController) HandleExport(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be either json or yaml"})
+		return
+	}
+
+	bundle, err := service.ExportQuestions(currentUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=questions."+format)
+	if format == "yaml" {
+		out, err := yaml.Marshal(bundle)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Data(http.StatusOK, "application/yaml", out)
+		return
+	}
+	ctx.JSON(http.StatusOK, bundle)
+}
+
+// HandleImport handles POST requests for importing a JSON or YAML question bundle, with a dryRun option and an
+// onConflict strategy (skip, overwrite or rename) for questions whose title is already used
+func (c *QuestionController) HandleImport(ctx *gin.Context) {
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	// YAML is a superset of JSON, so both formats are read the same way.
+	var bundle models.QuestionBundle
+	if err := yaml.Unmarshal(body, &bundle); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	report, err := service.ImportQuestions(currentUser(ctx), bundle, ctx.DefaultQuery("onConflict", models.ImportSkip), dryRun)
+	if err != nil {
+		if err == service.ErrUnsupportedBundle || err == service.ErrInvalidStrategy {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
 }
 
 // HandleRunTests handles POST requests to run tests on a solution
 func (c *QuestionController) HandleRunTests(ctx *gin.Context) {
 	var solution struct {
-		Id string `json:"id"`
+		Id       string `json:"id"`
 		Solution string `json:"solution"`
 		Language string `json:"language"`
 	}
@@ -115,21 +474,180 @@ func (c *QuestionController) HandleRunTests(ctx *gin.Context) {
 		return
 	}
 
-	out, err := service.RunTests(solution.Solution, solution.Id, solution.Language)
+	out, questionVersion, err := service.RunTests(solution.Solution, solution.Id, solution.Language)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	
-	ctx.JSON(http.StatusOK, gin.H{"message": out})
+
+	submission, err := service.CreateSubmission(currentUser(ctx).ID, solution.Id, questionVersion, solution.Language, solution.Solution, out)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": out, "submission": submission.ID}
+	if submission.Accepted {
+		percentile, err := service.GetSubmissionPercentile(submission)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response["runtimeMs"] = submission.RuntimeMs
+		response["memoryKb"] = submission.MemoryKb
+		response["percentile"] = percentile
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// HandleGetVersions handles GET requests for the version history of a question, with the changes made by each version
+func (c *QuestionController) HandleGetVersions(ctx *gin.Context) {
+	history, err := service.GetQuestionHistory(ctx.Param("id"), currentUser(ctx))
+	if err != nil {
+		if err == service.ErrQuestionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
+// HandleGetVersion handles GET requests for the full content of a question at a given version
+func (c *QuestionController) HandleGetVersion(ctx *gin.Context) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "version must be a number"})
+		return
+	}
+
+	snapshot, err := service.GetQuestionVersion(ctx.Param("id"), version, currentUser(ctx))
+	if err != nil {
+		if err == service.ErrQuestionNotFound || err == service.ErrVersionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if snapshot.Question.DescriptionHTML, err = service.RenderDescription(snapshot.Question); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	service.RedactQuestion(currentUser(ctx), &snapshot.Question)
+	snapshot.Question.Difficulty = models.Difficulties[snapshot.Question.Level]
+	ctx.JSON(http.StatusOK, snapshot)
+}
+
+// HandleRollback handles POST requests for restoring a previous version of a question as its newest version
+func (c *QuestionController) HandleRollback(ctx *gin.Context) {
+	id := ctx.Param("id")
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "version must be a number"})
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	if !authorizeManage(ctx, id) {
+		return
+	}
+
+	question, err := service.RollbackQuestion(id, version, currentUser(ctx).ID, expectedVersion)
+	writeQuestionUpdate(ctx, question, err)
+}
+
+// HandleGetHints handles GET requests for the hints of a question the current user revealed so far
+func (c *QuestionController) HandleGetHints(ctx *gin.Context) {
+	hints, err := service.GetRevealedHints(currentUser(ctx), ctx.Param("id"))
+	writeHintResponse(ctx, hints, err)
+}
+
+// HandleNextHint handles POST requests for revealing the next hint of a question to the current user
+func (c *QuestionController) HandleNextHint(ctx *gin.Context) {
+	hints, err := service.RevealNextHint(currentUser(ctx), ctx.Param("id"))
+	writeHintResponse(ctx, hints, err)
+}
+
+// HandleGetEditorial handles GET requests for the editorial of a question, available once the current user solved the question or gave up
+func (c *QuestionController) HandleGetEditorial(ctx *gin.Context) {
+	editorial, err := service.GetEditorial(currentUser(ctx), ctx.Param("id"))
+	writeHintResponse(ctx, editorial, err)
+}
+
+// HandleGiveUp handles POST requests for giving up on a question, which unlocks its editorial for the current user
+func (c *QuestionController) HandleGiveUp(ctx *gin.Context) {
+	editorial, err := service.GiveUp(currentUser(ctx), ctx.Param("id"))
+	writeHintResponse(ctx, editorial, err)
+}
+
+// writeHintResponse writes the response of a hint or editorial request
+func writeHintResponse(ctx *gin.Context, body interface{}, err error) {
+	if err != nil {
+		switch err {
+		case service.ErrQuestionNotFound, service.ErrNoMoreHints, service.ErrEditorialNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrEditorialLocked:
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, body)
+}
+
+// authorizeManage loads a question and checks that the current user may manage it.
+// It writes the error response and returns false when the request must not continue.
+func authorizeManage(ctx *gin.Context, id string) bool {
+	question, err := service.GetQuestionByID(id)
+	if err != nil {
+		if err == service.ErrQuestionNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Question not exist"})
+		return false
+	}
+
+	if !service.CanManageQuestion(currentUser(ctx), question) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the question author or an admin can modify this question"})
+		return false
+	}
+	return true
 }
 
 // RegisterHandlers registers all routes for the question controller
 func (c *QuestionController) RegisterHandlers(router *gin.Engine) {
+	manage := RequireRole(models.RoleAuthor, models.RoleAdmin)
+
 	router.GET("/questions", c.HandleGet)
+	router.GET("/questions/search", c.HandleSearch)
+	router.GET("/questions/export", manage, c.HandleExport)
+	router.POST("/questions/import", manage, c.HandleImport)
 	router.GET("/questions/:id", c.HandleGetByID)
-	router.POST("/questions", c.HandlePost)
-	router.PUT("/questions", c.HandlePut)
-	router.DELETE("/questions/:id", c.HandleDelete)
-	router.POST("/questions/runTests", c.HandleRunTests)
+	router.POST("/questions", manage, c.HandlePost)
+	router.PUT("/questions", manage, c.HandlePut)
+	router.PATCH("/questions/:id", manage, c.HandlePatch)
+	router.POST("/questions/:id/tests/upload", manage, c.HandleUploadTests)
+	router.POST("/questions/:id/tests/:index", manage, c.HandlePostTest)
+	router.PUT("/questions/:id/tests/:index", manage, c.HandlePutTest)
+	router.DELETE("/questions/:id/tests/:index", manage, c.HandleDeleteTest)
+	router.DELETE("/questions/:id", manage, c.HandleDelete)
+	router.GET("/questions/trash", manage, c.HandleGetTrash)
+	router.POST("/questions/:id/restore", manage, c.HandleRestore)
+	router.POST("/questions/runTests", RunTestsQuota(), c.HandleRunTests)
+	router.GET("/questions/:id/versions", c.HandleGetVersions)
+	router.GET("/questions/:id/versions/:version", c.HandleGetVersion)
+	router.POST("/questions/:id/versions/:version/rollback", manage, c.HandleRollback)
+	router.GET("/questions/:id/hints", c.HandleGetHints)
+	router.POST("/questions/:id/hints/next", c.HandleNextHint)
+	router.GET("/questions/:id/editorial", c.HandleGetEditorial)
+	router.POST("/questions/:id/giveup", c.HandleGiveUp)
 }
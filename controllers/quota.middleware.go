@@ -0,0 +1,31 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/services"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunTestsQuota limits how often and how many submissions a user can run at once.
+// Rejected requests get 429 Too Many Requests with a Retry-After header.
+func RunTestsQuota() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		client := ctx.ClientIP()
+		if user := currentUser(ctx); user != nil {
+			client = user.ID.Hex()
+		}
+
+		release, retryAfter, err := service.AcquireRunTests(client)
+		if err != nil {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		defer release()
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SandboxController struct{}
+
+// HandleGetPools handles GET requests for the size and usage of the warm sandbox pod pools
+func (c *SandboxController) HandleGetPools(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, service.GetPoolStats())
+}
+
+// RegisterHandlers registers all routes for the sandbox controller
+func (c *SandboxController) RegisterHandlers(router *gin.Engine) {
+	router.GET("/sandbox/pools", RequireRole(models.RoleAdmin), c.HandleGetPools)
+}
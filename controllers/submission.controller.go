@@ -0,0 +1,44 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubmissionController struct{}
+
+// HandleGetDistribution handles GET requests for the runtime or memory histogram of accepted submissions to a question
+func (c *SubmissionController) HandleGetDistribution(ctx *gin.Context) {
+	id := ctx.Param("id")
+	language := ctx.Query("language")
+	if language == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing language"})
+		return
+	}
+
+	buckets, err := strconv.Atoi(ctx.DefaultQuery("buckets", "20"))
+	if err != nil || buckets < 1 || buckets > 100 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "buckets must be a number between 1 and 100"})
+		return
+	}
+
+	distribution, err := service.GetDistribution(id, language, ctx.DefaultQuery("metric", "runtime"), buckets)
+	if err != nil {
+		if err == service.ErrUnsupportedMetric {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, distribution)
+}
+
+// RegisterHandlers registers all routes for the submission controller
+func (c *SubmissionController) RegisterHandlers(router *gin.Engine) {
+	router.GET("/questions/:id/distribution", c.HandleGetDistribution)
+}
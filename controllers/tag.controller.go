@@ -0,0 +1,68 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct{}
+
+// HandleGet handles GET requests for listing the tag taxonomy with the number of questions using each tag
+func (c *TagController) HandleGet(ctx *gin.Context) {
+	tags, err := service.GetAllTags(ctx.Query("category"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tags)
+}
+
+// HandlePost handles POST requests for adding a tag to the taxonomy
+func (c *TagController) HandlePost(ctx *gin.Context) {
+	var body models.Tag
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tag, err := service.CreateTag(body.Name, body.Category)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidTag:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrTagExists:
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+// HandleDelete handles DELETE requests for removing a tag no question uses from the taxonomy
+func (c *TagController) HandleDelete(ctx *gin.Context) {
+	err := service.DeleteTag(ctx.Param("name"))
+	if err != nil {
+		switch err {
+		case service.ErrTagNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrTagInUse:
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RegisterHandlers registers all routes for the tag controller
+func (c *TagController) RegisterHandlers(router *gin.Engine) {
+	router.GET("/tags", c.HandleGet)
+	router.POST("/tags", RequireRole(models.RoleAdmin), c.HandlePost)
+	router.DELETE("/tags/:name", RequireRole(models.RoleAdmin), c.HandleDelete)
+}
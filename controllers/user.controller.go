@@ -0,0 +1,131 @@
+package questioncontroller
+
+import (
+	"LeetCode-server/models"
+	"LeetCode-server/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct{}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// HandleRegister handles POST requests for creating a new user account
+func (c *UserController) HandleRegister(ctx *gin.Context) {
+	var body credentials
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	user, err := service.RegisterUser(body.Username, body.Password)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidUser:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUsernameTaken:
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// HandleLogin handles POST requests for exchanging credentials for a session token
+func (c *UserController) HandleLogin(ctx *gin.Context) {
+	var body credentials
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	token, user, err := service.Login(body.Username, body.Password)
+	if err != nil {
+		if err == service.ErrInvalidCredentials {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+}
+
+// HandleGetMe handles GET requests for the authenticated user
+func (c *UserController) HandleGetMe(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, currentUser(ctx))
+}
+
+// HandleGetProgress handles GET requests for the practice progress of the authenticated user, with the activity calendar
+// of the last days (default 365) counted in a time zone (default UTC)
+func (c *UserController) HandleGetProgress(ctx *gin.Context) {
+	// Local would be the time zone of the server, which MongoDB doesn't know by that name.
+	location, err := time.LoadLocation(ctx.DefaultQuery("timezone", "UTC"))
+	if err != nil || location == time.Local {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be an IANA time zone such as Europe/Paris"})
+		return
+	}
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "365"))
+	if err != nil || days < 1 || days > 366 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number between 1 and 366"})
+		return
+	}
+
+	progress, err := service.GetProgress(currentUser(ctx).ID, location, days)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, progress)
+}
+
+// HandleSetRole handles PUT requests for changing the role of a user
+func (c *UserController) HandleSetRole(ctx *gin.Context) {
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	user, err := service.SetUserRole(ctx.Param("id"), body.Role)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRole:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// PublicRoutes lists the routes of the user controller that do not require authentication
+func (c *UserController) PublicRoutes() []string {
+	return []string{"POST /auth/register", "POST /auth/login"}
+}
+
+// RegisterHandlers registers all routes for the user controller
+func (c *UserController) RegisterHandlers(router *gin.Engine) {
+	router.POST("/auth/register", c.HandleRegister)
+	router.POST("/auth/login", c.HandleLogin)
+	router.GET("/users/me", c.HandleGetMe)
+	router.GET("/users/me/progress", c.HandleGetProgress)
+	router.PUT("/users/:id/role", RequireRole(models.RoleAdmin), c.HandleSetRole)
+}
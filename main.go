@@ -1,26 +1,39 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"LeetCode-server/controllers"
 	"LeetCode-server/services"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
 	"time"
-	 "github.com/gin-contrib/cors"
 )
 
 func main() {
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"}, 
+		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
-   }))
+	}))
 	controller := &questioncontroller.QuestionController{}
+	submissionController := &questioncontroller.SubmissionController{}
+	userController := &questioncontroller.UserController{}
+	sandboxController := &questioncontroller.SandboxController{}
+	tagController := &questioncontroller.TagController{}
+	attachmentController := &questioncontroller.AttachmentController{}
+	listController := &questioncontroller.ListController{}
 	service.Init()
+	r.Use(questioncontroller.AuthMiddleware(append(userController.PublicRoutes(), attachmentController.PublicRoutes()...)...))
+	userController.RegisterHandlers(r)
 	controller.RegisterHandlers(r)
+	submissionController.RegisterHandlers(r)
+	sandboxController.RegisterHandlers(r)
+	tagController.RegisterHandlers(r)
+	attachmentController.RegisterHandlers(r)
+	listController.RegisterHandlers(r)
 
 	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
-}
\ No newline at end of file
+}
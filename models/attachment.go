@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Attachment is a file, such as a diagram, that a question description can reference by its URL
+// /questions/:id/attachments/:name.
+type Attachment struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// BundleFormatVersion is the version of the question bundle format written by exports and accepted by imports.
+const BundleFormatVersion = 1
+
+const (
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportRename    = "rename"
+)
+
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportRenamed     = "renamed"
+	ImportSkipped     = "skipped"
+	ImportFailed      = "failed"
+)
+
+// QuestionBundle is a portable set of questions used to move questions between deployments. It does not carry
+// database IDs, authors or versions, which only make sense within a deployment.
+type QuestionBundle struct {
+	FormatVersion int              `json:"formatVersion"`
+	ExportedAt    time.Time        `json:"exportedAt"`
+	Questions     []BundleQuestion `json:"questions"`
+}
+
+type BundleQuestion struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Level       int             `json:"level"`
+	InputTypes  string          `json:"inputTypes"`
+	OutputType  string          `json:"outputType"`
+	Tags        []string        `json:"tags,omitempty"`
+	Languages   []string        `json:"languages,omitempty"`
+	Tests       []BundleTest    `json:"tests"`
+	Examples    []BundleExample `json:"examples,omitempty"`
+	Hints       []string        `json:"hints,omitempty"`
+	Editorial   *Editorial      `json:"editorial,omitempty"`
+}
+
+type BundleExample struct {
+	Input       string `json:"input"`
+	Output      string `json:"output"`
+	Explanation string `json:"explanation,omitempty"`
+	TestIndex   *int   `json:"testIndex,omitempty"`
+}
+
+type BundleTest struct {
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expectedOutput"`
+}
+
+// ImportItemResult reports what an import did, or would do in a dry run, with one question of a bundle.
+type ImportItemResult struct {
+	Index      int    `json:"index"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	QuestionID string `json:"questionId,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun      bool               `json:"dryRun"`
+	Created     int                `json:"created"`
+	Overwritten int                `json:"overwritten"`
+	Renamed     int                `json:"renamed"`
+	Skipped     int                `json:"skipped"`
+	Failed      int                `json:"failed"`
+	Items       []ImportItemResult `json:"items"`
+}
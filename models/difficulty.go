@@ -0,0 +1,25 @@
+package models
+
+// Difficulties are stored as the question Level, whose values predate the named difficulties.
+const (
+	LevelEasy   = 1
+	LevelMedium = 2
+	LevelHard   = 3
+)
+
+// Difficulties maps the stored levels to their difficulty names.
+var Difficulties = map[int]string{
+	LevelEasy:   "Easy",
+	LevelMedium: "Medium",
+	LevelHard:   "Hard",
+}
+
+// QuestionStats are the submission statistics of a question. AverageAttempts is the average number of submissions the
+// users who solved the question made up to their first accepted one.
+type QuestionStats struct {
+	TotalSubmissions    int64   `json:"totalSubmissions"`
+	AcceptedSubmissions int64   `json:"acceptedSubmissions"`
+	AcceptanceRate      float64 `json:"acceptanceRate"`
+	Solvers             int64   `json:"solvers"`
+	AverageAttempts     float64 `json:"averageAttempts"`
+}
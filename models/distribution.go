@@ -0,0 +1,18 @@
+package models
+
+type Percentile struct {
+	Runtime float64 `json:"runtime"`
+	Memory  float64 `json:"memory"`
+	Total   int64   `json:"total"`
+}
+
+type HistogramBucket struct {
+	Min   float64 `bson:"min" json:"min"`
+	Max   float64 `bson:"max" json:"max"`
+	Count int64   `bson:"count" json:"count"`
+}
+
+type Distribution struct {
+	Metric  string            `json:"metric"`
+	Buckets []HistogramBucket `json:"buckets"`
+}
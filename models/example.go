@@ -0,0 +1,10 @@
+package models
+
+// Example is a worked example shown with a question. When TestIndex is set, the example is linked to the test at that
+// index of the question's tests and must have the same input and output, so that the judge verifies it.
+type Example struct {
+	Input       string `bson:"input"`
+	Output      string `bson:"output"`
+	Explanation string `bson:"explanation,omitempty"`
+	TestIndex   *int   `bson:"testIndex,omitempty" json:",omitempty"`
+}
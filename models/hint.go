@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Editorial is the write-up of how to solve a question, with reference solutions by language.
+type Editorial struct {
+	Approach  string            `bson:"approach" json:"approach"`
+	Solutions map[string]string `bson:"solutions,omitempty" json:"solutions,omitempty"`
+}
+
+// HintProgress records how many hints of a question a user revealed and whether they gave up on it.
+type HintProgress struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID        primitive.ObjectID `bson:"userId" json:"userId"`
+	QuestionID    primitive.ObjectID `bson:"questionId" json:"questionId"`
+	HintsRevealed int                `bson:"hintsRevealed" json:"hintsRevealed"`
+	GaveUpAt      *time.Time         `bson:"gaveUpAt,omitempty" json:"gaveUpAt,omitempty"`
+}
+
+// RevealedHints are the hints of a question a user revealed so far, in order.
+type RevealedHints struct {
+	Hints []string `json:"hints"`
+	Total int      `json:"total"`
+}
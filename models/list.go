@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Visibilities of a problem list: private lists are only seen by their owner, shared lists by anyone who has their ID,
+// and public lists are also listed for everyone.
+const (
+	VisibilityPrivate = "private"
+	VisibilityShared  = "shared"
+	VisibilityPublic  = "public"
+)
+
+// ProblemList is an ordered, named collection of questions, such as a study plan.
+type ProblemList struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	Description string               `bson:"description" json:"description"`
+	Visibility  string               `bson:"visibility" json:"visibility"`
+	OwnerID     primitive.ObjectID   `bson:"ownerId" json:"ownerId"`
+	QuestionIDs []primitive.ObjectID `bson:"questionIds" json:"questionIds"`
+	CreatedAt   time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time            `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ListProgress counts the questions of a list the current user solved and attempted.
+type ListProgress struct {
+	Total     int `json:"total"`
+	Solved    int `json:"solved"`
+	Attempted int `json:"attempted"`
+}
+
+// ProblemListDetail is a problem list with the summaries of its questions, in order, annotated with the current user's progress.
+// Deleted questions are left out.
+type ProblemListDetail struct {
+	ProblemList
+	Questions []QuestionSummary `json:"questions"`
+	Progress  ListProgress      `json:"progress"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type PoolStats struct {
+	Language   string `json:"language"`
+	Size       int    `json:"size"`
+	Ready      int    `json:"ready"`
+	InUse      int64  `json:"inUse"`
+	Created    int64  `json:"created"`
+	ColdStarts int64  `json:"coldStarts"`
+}
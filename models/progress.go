@@ -0,0 +1,28 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Statuses of a question for a user, shown in question listings.
+const (
+	StatusSolved    = "solved"
+	StatusAttempted = "attempted"
+)
+
+// ActivityDay counts the submissions a user made on a day, formatted as YYYY-MM-DD.
+type ActivityDay struct {
+	Date        string `bson:"_id" json:"date"`
+	Submissions int    `bson:"submissions" json:"submissions"`
+	Accepted    int    `bson:"accepted" json:"accepted"`
+}
+
+// Progress summarizes the practice of a user. Attempted lists the questions submitted to but not solved yet, and the
+// counts by difficulty and tag are of solved questions. A streak is a run of consecutive days with submissions.
+type Progress struct {
+	Solved             []primitive.ObjectID `json:"solved"`
+	Attempted          []primitive.ObjectID `json:"attempted"`
+	SolvedByDifficulty map[string]int       `json:"solvedByDifficulty"`
+	SolvedByTag        map[string]int       `json:"solvedByTag"`
+	Calendar           []ActivityDay        `json:"calendar"`
+	CurrentStreak      int                  `json:"currentStreak"`
+	LongestStreak      int                  `json:"longestStreak"`
+}
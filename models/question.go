@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -8,8 +10,89 @@ type Question struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
 	Title       string             `bson:"title"`
 	Description string             `bson:"description"`
-	Level       int                `bson:"level"`
-	Tests       []Test             `bson:"tests"` 
-	InputTypes string            `bson:"inputTypes"`
-	OutputType string            `bson:"outputType"`
+	// DescriptionHTML is the Markdown description rendered to sanitized HTML. It is computed when a question is read and never stored.
+	DescriptionHTML string `bson:"-"`
+	Level           int    `bson:"level"`
+	// Difficulty is the name of the Level. It is computed when a question is read, and can be sent instead of Level when writing one.
+	Difficulty string `bson:"-"`
+	// Stats are computed from the submissions when a question is read and never stored.
+	Stats    *QuestionStats `bson:"-" json:",omitempty"`
+	Tests    []Test         `bson:"tests"`
+	Examples []Example      `bson:"examples,omitempty"`
+	// Hints and Editorial are only shown in full to the users who manage the question; solvers reveal them progressively.
+	Hints     []string   `bson:"hints,omitempty"`
+	Editorial *Editorial `bson:"editorial,omitempty"`
+	// HintCount is the number of hints, computed when a question is read and never stored.
+	HintCount  int                `bson:"-"`
+	InputTypes string             `bson:"inputTypes"`
+	OutputType string             `bson:"outputType"`
+	AuthorID   primitive.ObjectID `bson:"authorId,omitempty"`
+	Tags       []string           `bson:"tags,omitempty"`
+	Languages  []string           `bson:"languages,omitempty"`
+	Version    int                `bson:"version"`
+	DeletedAt  *time.Time         `bson:"deletedAt,omitempty"`
+	DeletedBy  primitive.ObjectID `bson:"deletedBy,omitempty"`
+}
+
+// QuestionSummary is the lightweight projection of a question returned by question listings.
+type QuestionSummary struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Title      string             `bson:"title"`
+	Level      int                `bson:"level"`
+	Difficulty string             `bson:"-"`
+	Tags       []string           `bson:"tags"`
+	Languages  []string           `bson:"languages"`
+	AuthorID   primitive.ObjectID `bson:"authorId,omitempty"`
+	DeletedAt  *time.Time         `bson:"deletedAt,omitempty"`
+	// Status is whether the current user solved or attempted the question, empty otherwise.
+	Status string `bson:"-" json:",omitempty"`
+}
+
+// QuestionFilter holds the filtering, sorting and pagination options of a question listing.
+type QuestionFilter struct {
+	Level    int
+	Tag      string
+	Language string
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+type QuestionPage struct {
+	Questions []QuestionSummary `json:"questions"`
+	Total     int64             `json:"total"`
+	Page      int               `json:"page"`
+	PageSize  int               `json:"pageSize"`
+}
+
+// QuestionSearchResult is a question summary matching a search, with its relevance score and a highlighted description snippet.
+type QuestionSearchResult struct {
+	QuestionSummary `bson:",inline"`
+	Score           float64 `bson:"score"`
+	Snippet         string  `bson:"-"`
+	Description     string  `bson:"description" json:"-"`
+}
+
+type QuestionSearchPage struct {
+	Results  []QuestionSearchResult `json:"results"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"pageSize"`
+}
+
+// QuestionFields are the fields of a question that PATCH /questions/:id changes. A JSON Merge Patch uses the same keys as
+// the question returned by GET /questions/:id. Level and Difficulty are two ways of writing the same field.
+type QuestionFields struct {
+	Title       string
+	Description string
+	Level       int
+	Difficulty  string
+	InputTypes  string
+	OutputType  string
+	Tags        []string
+	Languages   []string
+	Tests       []Test
+	Examples    []Example
+	Hints       []string
+	Editorial   *Editorial
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuestionVersion is an immutable snapshot of a question, stored every time the question is created or updated.
+type QuestionVersion struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	QuestionID primitive.ObjectID `bson:"questionId" json:"questionId"`
+	Version    int                `bson:"version" json:"version"`
+	EditorID   primitive.ObjectID `bson:"editorId,omitempty" json:"editorId"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	Question   Question           `bson:"question" json:"question"`
+}
+
+// FieldChange describes how a field of a question changed from one version to the next.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// QuestionVersionSummary is an entry of a question history: who made a version and how it differs from the previous one.
+type QuestionVersionSummary struct {
+	Version   int                `json:"version"`
+	EditorID  primitive.ObjectID `json:"editorId"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Changes   []FieldChange      `json:"changes"`
+}
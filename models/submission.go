@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Submission struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	QuestionID primitive.ObjectID `bson:"questionId" json:"questionId"`
+	// QuestionVersion is the version of the question whose tests the submission ran against.
+	QuestionVersion int                `bson:"questionVersion" json:"questionVersion"`
+	UserID          primitive.ObjectID `bson:"userId" json:"userId"`
+	Language        string             `bson:"language" json:"language"`
+	Code            string             `bson:"code" json:"code"`
+	Accepted        bool               `bson:"accepted" json:"accepted"`
+	RuntimeMs       float64            `bson:"runtimeMs" json:"runtimeMs"`
+	MemoryKb        int64              `bson:"memoryKb" json:"memoryKb"`
+	Results         []TestResult       `bson:"results" json:"results"`
+	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	TagCategoryTopic   = "topic"
+	TagCategoryCompany = "company"
+)
+
+type Tag struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name          string             `bson:"name" json:"name"`
+	Category      string             `bson:"category" json:"category"`
+	QuestionCount int64              `bson:"-" json:"questionCount"`
+}
@@ -1,6 +1,12 @@
 package models
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Test is a test case of a question. Inputs and expected outputs too large to be stored inline are kept in GridFS
+// and referenced by InputFile and ExpectedOutputFile, with the inline field left empty.
 type Test struct {
-	Input          string `bson:"input"`         
-	ExpectedOutput string `bson:"expected_output"` 
-}
\ No newline at end of file
+	Input              string              `bson:"input"`
+	ExpectedOutput     string              `bson:"expected_output"`
+	InputFile          *primitive.ObjectID `bson:"inputFile,omitempty" json:",omitempty"`
+	ExpectedOutputFile *primitive.ObjectID `bson:"expectedOutputFile,omitempty" json:",omitempty"`
+}
@@ -1,11 +1,14 @@
 package models
 
 type TestResult struct {
-	TestNumber      int      `json:"test_number"`
-	Passed          bool     `json:"passed"`
-	Output          string   `json:"output"`
-	Input           string   `json:"input"`
-	ExpectedOutput  string   `json:"expectedOutput"`
-	Comments        string   `json:"comments"`
-	Errors          []ErrorLine `json:"errors"`
+	TestNumber     int         `json:"test_number"`
+	Passed         bool        `json:"passed"`
+	Output         string      `json:"output"`
+	Input          string      `json:"input"`
+	ExpectedOutput string      `json:"expectedOutput"`
+	Comments       string      `json:"comments"`
+	Errors         []ErrorLine `json:"errors"`
+	RuntimeMs      float64     `json:"runtimeMs"`
+	MemoryKb       int64       `json:"memoryKb"`
+	InternalError  bool        `json:"internalError"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleAuthor = "author"
+	RoleSolver = "solver"
+)
+
+type User struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username     string             `bson:"username" json:"username"`
+	PasswordHash string             `bson:"passwordHash" json:"-"`
+	Role         string             `bson:"role" json:"role"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+}
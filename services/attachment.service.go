@@ -0,0 +1,207 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var attachmentBucket *gridfs.Bucket
+
+// AttachmentSizeLimit is the maximum size in bytes of an attachment.
+var AttachmentSizeLimit int64
+
+// attachmentTypes lists the content types attachments may have. SVG and HTML are left out because they can run scripts
+// when opened from the server's origin.
+var attachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+var attachmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// initAttachments sets up the GridFS bucket holding question attachments and reads their maximum size from
+// ATTACHMENT_MAX_MB (default 5).
+func initAttachments() {
+	var err error
+	attachmentBucket, err = gridfs.NewBucket(database, options.GridFSBucket().SetName("attachments"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = database.Collection("attachments.files").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "metadata.questionId", Value: 1}, {Key: "filename", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	AttachmentSizeLimit = int64(envInt("ATTACHMENT_MAX_MB", 5)) * 1024 * 1024
+}
+
+// attachmentFile is the GridFS files document of an attachment.
+type attachmentFile struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"filename"`
+	Length     int64              `bson:"length"`
+	UploadDate primitive.DateTime `bson:"uploadDate"`
+	Metadata   struct {
+		QuestionID  primitive.ObjectID `bson:"questionId"`
+		ContentType string             `bson:"contentType"`
+		UploadedBy  primitive.ObjectID `bson:"uploadedBy"`
+	} `bson:"metadata"`
+}
+
+func (f attachmentFile) attachment() models.Attachment {
+	return models.Attachment{Name: f.Name, ContentType: f.Metadata.ContentType, Size: f.Length, UploadedAt: f.UploadDate.Time()}
+}
+
+// UploadAttachment stores a file under a name among the attachments of a question. The content type is detected from
+// the content rather than trusted from the client, and must be one of attachmentTypes.
+func UploadAttachment(id string, uploaderID primitive.ObjectID, name string, content []byte) (*models.Attachment, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !attachmentNamePattern.MatchString(name) {
+		return nil, ErrInvalidAttachmentName
+	}
+	if int64(len(content)) > AttachmentSizeLimit {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !attachmentTypes[contentType] {
+		return nil, ErrUnsupportedAttachment
+	}
+
+	if _, err := findAttachment(question.ID, name); err == nil {
+		return nil, ErrAttachmentExists
+	} else if err != ErrAttachmentNotFound {
+		return nil, err
+	}
+
+	metadata := bson.M{"questionId": question.ID, "contentType": contentType, "uploadedBy": uploaderID}
+	fileID, err := attachmentBucket.UploadFromStream(name, bytes.NewReader(content), options.GridFSUpload().SetMetadata(metadata))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrAttachmentExists
+		}
+		return nil, err
+	}
+
+	file, err := findAttachmentByID(fileID)
+	if err != nil {
+		return nil, err
+	}
+	attachment := file.attachment()
+	return &attachment, nil
+}
+
+// GetAttachments lists the attachments of a question by name.
+func GetAttachments(id string) ([]models.Attachment, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := attachmentBucket.GetFilesCollection().Find(context.Background(),
+		bson.M{"metadata.questionId": question.ID}, options.Find().SetSort(bson.M{"filename": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var files []attachmentFile
+	if err := cursor.All(context.Background(), &files); err != nil {
+		return nil, err
+	}
+
+	attachments := []models.Attachment{}
+	for _, file := range files {
+		attachments = append(attachments, file.attachment())
+	}
+	return attachments, nil
+}
+
+// OpenAttachment returns an attachment of a question and a reader of its content, which the caller must close.
+func OpenAttachment(id, name string) (*models.Attachment, io.ReadCloser, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := findAttachment(question.ID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stream, err := attachmentBucket.OpenDownloadStream(file.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment := file.attachment()
+	return &attachment, stream, nil
+}
+
+// DeleteAttachment removes an attachment of a question.
+func DeleteAttachment(id, name string) error {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return err
+	}
+	file, err := findAttachment(question.ID, name)
+	if err != nil {
+		return err
+	}
+	return attachmentBucket.Delete(file.ID)
+}
+
+// deleteQuestionAttachments removes every attachment of the given questions.
+func deleteQuestionAttachments(questionIDs []primitive.ObjectID) error {
+	cursor, err := attachmentBucket.GetFilesCollection().Find(context.Background(),
+		bson.M{"metadata.questionId": bson.M{"$in": questionIDs}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var files []attachmentFile
+	if err := cursor.All(context.Background(), &files); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := attachmentBucket.Delete(file.ID); err != nil && err != gridfs.ErrFileNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func findAttachment(questionID primitive.ObjectID, name string) (*attachmentFile, error) {
+	return decodeAttachment(bson.M{"metadata.questionId": questionID, "filename": name})
+}
+
+func findAttachmentByID(fileID primitive.ObjectID) (*attachmentFile, error) {
+	return decodeAttachment(bson.M{"_id": fileID})
+}
+
+func decodeAttachment(filter bson.M) (*attachmentFile, error) {
+	var file attachmentFile
+	err := attachmentBucket.GetFilesCollection().FindOne(context.Background(), filter).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &file, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportQuestions returns every question that is not deleted as a portable bundle, with the test data stored in GridFS
+// inlined. The hints and editorial (including the reference solutions) are only exported for the questions the user
+// can manage, as they are hidden from the other users.
+func ExportQuestions(user *models.User) (*models.QuestionBundle, error) {
+	questions, err := GetAllQuestions()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := models.QuestionBundle{
+		FormatVersion: models.BundleFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Questions:     []models.BundleQuestion{},
+	}
+	for _, question := range questions {
+		item := models.BundleQuestion{
+			Title:       question.Title,
+			Description: question.Description,
+			Level:       question.Level,
+			InputTypes:  question.InputTypes,
+			OutputType:  question.OutputType,
+			Tags:        question.Tags,
+			Languages:   question.Languages,
+			Tests:       []models.BundleTest{},
+		}
+		if CanManageQuestion(user, &question) {
+			item.Hints = question.Hints
+			item.Editorial = question.Editorial
+		}
+		for _, test := range question.Tests {
+			test, err := loadTest(test)
+			if err != nil {
+				return nil, err
+			}
+			item.Tests = append(item.Tests, models.BundleTest{Input: test.Input, ExpectedOutput: test.ExpectedOutput})
+		}
+		for _, example := range question.Examples {
+			item.Examples = append(item.Examples, models.BundleExample(example))
+		}
+		bundle.Questions = append(bundle.Questions, item)
+	}
+	return &bundle, nil
+}
+
+// ImportQuestions creates the questions of a bundle on behalf of the importing user, and reports what happened to each of them.
+// A question conflicts with an existing one when they have the same title; the strategy decides whether the bundle question
+// is skipped, overwrites the existing question (which the user must be allowed to manage) or is created under a new title.
+// Invalid questions are reported and don't stop the import. With dryRun, nothing is written and the report tells what the import would do.
+func ImportQuestions(user *models.User, bundle models.QuestionBundle, strategy string, dryRun bool) (*models.ImportReport, error) {
+	if bundle.FormatVersion != models.BundleFormatVersion {
+		return nil, ErrUnsupportedBundle
+	}
+	if strategy != models.ImportSkip && strategy != models.ImportOverwrite && strategy != models.ImportRename {
+		return nil, ErrInvalidStrategy
+	}
+
+	// imported holds the titles created by this import, so that duplicates within the bundle conflict with each other
+	// during dry runs too.
+	imported := map[string]primitive.ObjectID{}
+	report := models.ImportReport{DryRun: dryRun, Items: []models.ImportItemResult{}}
+	for i, item := range bundle.Questions {
+		result := importQuestion(user, item, strategy, dryRun, imported)
+		result.Index = i
+		switch result.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportOverwritten:
+			report.Overwritten++
+		case models.ImportRenamed:
+			report.Renamed++
+		case models.ImportSkipped:
+			report.Skipped++
+		case models.ImportFailed:
+			report.Failed++
+		}
+		report.Items = append(report.Items, result)
+	}
+	return &report, nil
+}
+
+// importQuestion applies the conflict strategy to one question of a bundle.
+func importQuestion(user *models.User, item models.BundleQuestion, strategy string, dryRun bool, imported map[string]primitive.ObjectID) models.ImportItemResult {
+	result := models.ImportItemResult{Title: item.Title}
+	fail := func(err error) models.ImportItemResult {
+		result.Status = models.ImportFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	question := models.Question{
+		Title:       item.Title,
+		Description: item.Description,
+		Level:       item.Level,
+		InputTypes:  item.InputTypes,
+		OutputType:  item.OutputType,
+		Tags:        item.Tags,
+		Languages:   item.Languages,
+		Hints:       item.Hints,
+		Editorial:   item.Editorial,
+	}
+	for _, test := range item.Tests {
+		question.Tests = append(question.Tests, models.Test{Input: test.Input, ExpectedOutput: test.ExpectedOutput})
+	}
+	for _, example := range item.Examples {
+		question.Examples = append(question.Examples, models.Example(example))
+	}
+	if err := validateQuestion(question); err != nil {
+		return fail(err)
+	}
+	if _, err := normalizeQuestionTags(question.Tags); err != nil {
+		return fail(err)
+	}
+
+	existingID, exists, err := findTitle(question.Title, imported)
+	if err != nil {
+		return fail(err)
+	}
+
+	status := models.ImportCreated
+	if exists {
+		switch strategy {
+		case models.ImportSkip:
+			result.Status = models.ImportSkipped
+			if !existingID.IsZero() {
+				result.QuestionID = existingID.Hex()
+			}
+			return result
+
+		case models.ImportOverwrite:
+			if _, ok := imported[question.Title]; !ok {
+				existing, err := GetQuestionByID(existingID.Hex())
+				if err != nil {
+					return fail(err)
+				}
+				if !CanManageQuestion(user, existing) {
+					return fail(errors.New("Only the question author or an admin can modify this question"))
+				}
+			}
+			result.Status = models.ImportOverwritten
+			if dryRun {
+				if !existingID.IsZero() {
+					result.QuestionID = existingID.Hex()
+				}
+				return result
+			}
+			if _, err := UpdateQuestion(existingID.Hex(), user.ID, 0, question); err != nil {
+				return fail(err)
+			}
+			result.QuestionID = existingID.Hex()
+			return result
+
+		case models.ImportRename:
+			if question.Title, err = uniqueTitle(question.Title, imported); err != nil {
+				return fail(err)
+			}
+			result.Title = question.Title
+			status = models.ImportRenamed
+		}
+	}
+
+	var questionID primitive.ObjectID
+	if !dryRun {
+		created, err := CreateQuestion(user.ID, question)
+		if err != nil {
+			return fail(err)
+		}
+		questionID = created.InsertedID.(primitive.ObjectID)
+		result.QuestionID = questionID.Hex()
+	}
+	imported[question.Title] = questionID
+	result.Status = status
+	return result
+}
+
+// findTitle looks for a question with the given title among the questions that are not deleted and those created by
+// the current import. The ID of a question created by a dry run is the nil ObjectID.
+func findTitle(title string, imported map[string]primitive.ObjectID) (primitive.ObjectID, bool, error) {
+	if id, ok := imported[title]; ok {
+		return id, true, nil
+	}
+
+	var question models.Question
+	err := questionCollection.FindOne(context.Background(),
+		bson.M{"title": title, "deletedAt": bson.M{"$exists": false}},
+		options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&question)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return primitive.NilObjectID, false, nil
+		}
+		return primitive.NilObjectID, false, err
+	}
+	return question.ID, true, nil
+}
+
+// uniqueTitle returns the first of "title (2)", "title (3)", ... that no question uses yet.
+func uniqueTitle(title string, imported map[string]primitive.ObjectID) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", title, n)
+		_, exists, err := findTitle(candidate, imported)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+}
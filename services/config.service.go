@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// sandboxConfig describes where and how submission pods run. It is read from the YAML or JSON file named by
+// SANDBOX_CONFIG, and every field left out keeps its default value.
+type sandboxConfig struct {
+	Namespace        string                    `json:"namespace"`
+	PodStartTimeout  metav1.Duration           `json:"podStartTimeout"`
+	NodeSelector     map[string]string         `json:"nodeSelector"`
+	Tolerations      []corev1.Toleration       `json:"tolerations"`
+	RuntimeClassName string                    `json:"runtimeClassName"`
+	ImagePullSecrets []string                  `json:"imagePullSecrets"`
+	Languages        map[string]languageConfig `json:"languages"`
+}
+
+// languageConfig holds the sandbox settings of a single language.
+type languageConfig struct {
+	Image     string                      `json:"image"`
+	PoolSize  *int                        `json:"poolSize"`
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+var sandbox sandboxConfig
+
+// defaultSandboxConfig returns the settings used when no configuration file is given.
+func defaultSandboxConfig() sandboxConfig {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+			corev1.ResourceCPU:    resource.MustParse("1"),
+		},
+	}
+	poolSize := 2
+	return sandboxConfig{
+		Namespace:       "default",
+		PodStartTimeout: metav1.Duration{Duration: 2 * time.Minute},
+		Languages: map[string]languageConfig{
+			"java":   {Image: "miryamw/java-test:latest", PoolSize: &poolSize, Resources: resources},
+			"python": {Image: "miryamw/python-test:latest", PoolSize: &poolSize, Resources: resources},
+		},
+	}
+}
+
+// initSandboxConfig loads and validates the sandbox configuration. SANDBOX_NAMESPACE and SANDBOX_POOL_SIZE_<LANGUAGE>
+// override the values of the file. It stops the server when the configuration is invalid.
+func initSandboxConfig() {
+	sandbox = defaultSandboxConfig()
+
+	if path := os.Getenv("SANDBOX_CONFIG"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read SANDBOX_CONFIG: %v", err)
+		}
+		defaults := sandbox.Languages
+		sandbox.Languages = nil
+		if err := yaml.UnmarshalStrict(content, &sandbox); err != nil {
+			log.Fatalf("failed to parse SANDBOX_CONFIG: %v", err)
+		}
+		sandbox.Languages = mergeLanguages(defaults, sandbox.Languages)
+	}
+
+	if namespace := os.Getenv("SANDBOX_NAMESPACE"); namespace != "" {
+		sandbox.Namespace = namespace
+	}
+	for language, config := range sandbox.Languages {
+		poolSize := 0
+		if config.PoolSize != nil {
+			poolSize = *config.PoolSize
+		}
+		poolSize = envInt("SANDBOX_POOL_SIZE_"+strings.ToUpper(language), poolSize)
+		config.PoolSize = &poolSize
+		sandbox.Languages[language] = config
+	}
+
+	if err := sandbox.validate(); err != nil {
+		log.Fatalf("invalid sandbox configuration: %v", err)
+	}
+}
+
+// mergeLanguages fills the fields missing from the configured languages with their defaults. Resource requests and
+// limits are merged by resource name, so that setting only the memory limit keeps the other defaults.
+func mergeLanguages(defaults, configured map[string]languageConfig) map[string]languageConfig {
+	for language, config := range configured {
+		def, ok := defaults[language]
+		if !ok {
+			// Unknown languages are kept so that validate reports them.
+			defaults[language] = config
+			continue
+		}
+		if config.Image != "" {
+			def.Image = config.Image
+		}
+		if config.PoolSize != nil {
+			def.PoolSize = config.PoolSize
+		}
+		// The defaults of the languages share their resource lists, which must not be changed in place.
+		def.Resources = *def.Resources.DeepCopy()
+		def.Resources.Requests = mergeResources(def.Resources.Requests, config.Resources.Requests)
+		def.Resources.Limits = mergeResources(def.Resources.Limits, config.Resources.Limits)
+		defaults[language] = def
+	}
+	return defaults
+}
+
+// mergeResources returns the default resource quantities overridden by the configured ones.
+func mergeResources(defaults, configured corev1.ResourceList) corev1.ResourceList {
+	if len(configured) == 0 {
+		return defaults
+	}
+	if defaults == nil {
+		defaults = corev1.ResourceList{}
+	}
+	for name, quantity := range configured {
+		defaults[name] = quantity
+	}
+	return defaults
+}
+
+// validate checks the configuration before any pod is created with it.
+func (c *sandboxConfig) validate() error {
+	if errs := validation.IsDNS1123Label(c.Namespace); len(errs) > 0 {
+		return fmt.Errorf("namespace %q: %s", c.Namespace, strings.Join(errs, ", "))
+	}
+	if c.PodStartTimeout.Duration <= 0 {
+		return fmt.Errorf("podStartTimeout must be positive")
+	}
+	if c.RuntimeClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(c.RuntimeClassName); len(errs) > 0 {
+			return fmt.Errorf("runtimeClassName %q: %s", c.RuntimeClassName, strings.Join(errs, ", "))
+		}
+	}
+	for _, secret := range c.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(secret); len(errs) > 0 {
+			return fmt.Errorf("imagePullSecrets %q: %s", secret, strings.Join(errs, ", "))
+		}
+	}
+	for key, value := range c.NodeSelector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("nodeSelector key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("nodeSelector value %q: %s", value, strings.Join(errs, ", "))
+		}
+	}
+	for _, toleration := range c.Tolerations {
+		if toleration.Operator == corev1.TolerationOpExists && toleration.Value != "" {
+			return fmt.Errorf("toleration %q: value must be empty when operator is Exists", toleration.Key)
+		}
+	}
+
+	for language, config := range c.Languages {
+		if _, ok := sandboxPools[language]; !ok {
+			return fmt.Errorf("languages.%s: unsupported language", language)
+		}
+		if config.Image == "" {
+			return fmt.Errorf("languages.%s.image is required", language)
+		}
+		if config.PoolSize != nil && *config.PoolSize < 0 {
+			return fmt.Errorf("languages.%s.poolSize must not be negative", language)
+		}
+		for name, limit := range config.Resources.Limits {
+			if request, ok := config.Resources.Requests[name]; ok && request.Cmp(limit) > 0 {
+				return fmt.Errorf("languages.%s.resources: %s request %s exceeds limit %s", language, name, request.String(), limit.String())
+			}
+		}
+	}
+	return nil
+}
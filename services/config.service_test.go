@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMergeLanguages(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		language string
+		image    string
+		poolSize int
+		requests map[corev1.ResourceName]string
+		limits   map[corev1.ResourceName]string
+	}{
+		{
+			name:     "defaults",
+			config:   `{}`,
+			language: "java",
+			image:    "miryamw/java-test:latest",
+			poolSize: 2,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi", corev1.ResourceCPU: "1"},
+		},
+		{
+			name:     "image",
+			config:   `{"languages": {"python": {"image": "registry/python:3"}}}`,
+			language: "python",
+			image:    "registry/python:3",
+			poolSize: 2,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi", corev1.ResourceCPU: "1"},
+		},
+		{
+			name:     "pool disabled",
+			config:   `{"languages": {"java": {"poolSize": 0}}}`,
+			language: "java",
+			image:    "miryamw/java-test:latest",
+			poolSize: 0,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi", corev1.ResourceCPU: "1"},
+		},
+		{
+			name:     "only memory limit",
+			config:   `{"languages": {"java": {"resources": {"limits": {"memory": "2Gi"}}}}}`,
+			language: "java",
+			image:    "miryamw/java-test:latest",
+			poolSize: 2,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "2Gi", corev1.ResourceCPU: "1"},
+		},
+		{
+			name:     "other language keeps defaults",
+			config:   `{"languages": {"java": {"resources": {"limits": {"memory": "2Gi"}}}}}`,
+			language: "python",
+			image:    "miryamw/python-test:latest",
+			poolSize: 2,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi", corev1.ResourceCPU: "1"},
+		},
+		{
+			name:     "new resource",
+			config:   `{"languages": {"python": {"resources": {"requests": {"ephemeral-storage": "1Gi"}}}}}`,
+			language: "python",
+			image:    "miryamw/python-test:latest",
+			poolSize: 2,
+			requests: map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi", corev1.ResourceCPU: "500m", corev1.ResourceEphemeralStorage: "1Gi"},
+			limits:   map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi", corev1.ResourceCPU: "1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var config sandboxConfig
+			if err := yaml.UnmarshalStrict([]byte(test.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			languages := mergeLanguages(defaultSandboxConfig().Languages, config.Languages)
+
+			language := languages[test.language]
+			if language.Image != test.image {
+				t.Errorf("image = %q, want %q", language.Image, test.image)
+			}
+			if language.PoolSize == nil || *language.PoolSize != test.poolSize {
+				t.Errorf("poolSize = %v, want %d", language.PoolSize, test.poolSize)
+			}
+			checkResources(t, "requests", language.Resources.Requests, test.requests)
+			checkResources(t, "limits", language.Resources.Limits, test.limits)
+		})
+	}
+}
+
+func checkResources(t *testing.T, name string, got corev1.ResourceList, want map[corev1.ResourceName]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for resourceName, quantity := range want {
+		if value, ok := got[resourceName]; !ok || value.Cmp(resource.MustParse(quantity)) != 0 {
+			t.Errorf("%s[%s] = %s, want %s", name, resourceName, value.String(), quantity)
+		}
+	}
+}
+
+func TestSandboxConfigValidate(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name   string
+		modify func(*sandboxConfig)
+		err    string
+	}{
+		{"defaults", func(c *sandboxConfig) {}, ""},
+		{"namespace", func(c *sandboxConfig) { c.Namespace = "Not_A_Label" }, "namespace"},
+		{"timeout", func(c *sandboxConfig) { c.PodStartTimeout = metav1.Duration{Duration: -time.Second} }, "podStartTimeout"},
+		{"runtime class", func(c *sandboxConfig) { c.RuntimeClassName = "gVisor!" }, "runtimeClassName"},
+		{"runtime class valid", func(c *sandboxConfig) { c.RuntimeClassName = "gvisor" }, ""},
+		{"pull secret", func(c *sandboxConfig) { c.ImagePullSecrets = []string{"bad secret"} }, "imagePullSecrets"},
+		{"node selector key", func(c *sandboxConfig) { c.NodeSelector = map[string]string{"bad key": "x"} }, "nodeSelector key"},
+		{"node selector value", func(c *sandboxConfig) { c.NodeSelector = map[string]string{"workload": "bad value"} }, "nodeSelector value"},
+		{"toleration", func(c *sandboxConfig) {
+			c.Tolerations = []corev1.Toleration{{Key: "submissions", Operator: corev1.TolerationOpExists, Value: "x"}}
+		}, "toleration"},
+		{"unsupported language", func(c *sandboxConfig) { c.Languages["cobol"] = languageConfig{Image: "cobol"} }, "unsupported language"},
+		{"missing image", func(c *sandboxConfig) {
+			java := c.Languages["java"]
+			java.Image = ""
+			c.Languages["java"] = java
+		}, "image is required"},
+		{"negative pool size", func(c *sandboxConfig) {
+			java := c.Languages["java"]
+			java.PoolSize = &negative
+			c.Languages["java"] = java
+		}, "poolSize"},
+		{"request above limit", func(c *sandboxConfig) {
+			java := c.Languages["java"]
+			java.Resources = corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}
+			c.Languages["java"] = java
+		}, "exceeds limit"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := defaultSandboxConfig()
+			test.modify(&config)
+			err := config.validate()
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("validate() error = %v, want an error about %s", err, test.err)
+			}
+		})
+	}
+}
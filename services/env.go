@@ -0,0 +1,21 @@
+package service
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// envInt reads a non-negative integer from an environment variable, falling back to def when it is unset.
+// It stops the server when the variable is set to something else.
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package service
+
+import "errors"
+
+// Errors returned by the services that the controllers map to client error responses.
+var (
+	ErrUnsupportedMetric   = errors.New("Metric must be either runtime or memory")
+	ErrInvalidUser         = errors.New("User must contain a username & a password of at least 8 characters")
+	ErrUsernameTaken       = errors.New("Username already exists")
+	ErrInvalidCredentials  = errors.New("Invalid username or password")
+	ErrInvalidToken        = errors.New("Invalid or expired token")
+	ErrInvalidRole         = errors.New("Role must be one of admin, author or solver")
+	ErrUserNotFound        = errors.New("User not found")
+	ErrQuestionNotFound    = errors.New("Question not found")
+	ErrInvalidQuestion     = errors.New("Question must contain title & description & level & at least one test")
+	ErrInvalidSort         = errors.New("Sort must be one of title, level or created, optionally prefixed with -")
+	ErrUnsupportedLanguage = errors.New("Language must be either java or python")
+	ErrEmptySearch         = errors.New("Missing search text")
+	ErrInvalidTag          = errors.New("Tag must contain a name and a category of either topic or company")
+	ErrTagExists           = errors.New("Tag already exists")
+	ErrTagNotFound         = errors.New("Tag not found")
+	ErrTagInUse            = errors.New("Tag is still used by questions, remove it from them first")
+	ErrUnknownTag          = errors.New("Unknown tags, ask an admin to add them to the taxonomy")
+	ErrVersionNotFound     = errors.New("Question version not found")
+	ErrVersionConflict     = errors.New("Question was modified by someone else, reload it and retry")
+	ErrInvalidPatch        = errors.New("Invalid merge patch")
+	ErrTestNotFound        = errors.New("Test not found")
+	ErrInvalidList         = errors.New("List must contain a name, a visibility of private, shared or public and at most 500 questions")
+	ErrListNotFound        = errors.New("List not found")
+	ErrInvalidDifficulty   = errors.New("Difficulty must be one of Easy, Medium or Hard")
+	ErrInvalidExample      = errors.New("Invalid example")
+	ErrNoMoreHints         = errors.New("No more hints")
+	ErrEditorialNotFound   = errors.New("Question has no editorial")
+	ErrEditorialLocked     = errors.New("The editorial unlocks after an accepted submission or giving up")
+	ErrInvalidTestArchive  = errors.New("Test archive must be a zip of matching N.in and N.out files")
+	ErrTestArchiveTooLarge = errors.New("Test archive is too large")
+	ErrUnsupportedBundle   = errors.New("Unsupported bundle format version")
+	ErrInvalidStrategy     = errors.New("Conflict strategy must be one of skip, overwrite or rename")
+	ErrRateLimited         = errors.New("Too many submissions, please retry later")
+	ErrTooManyRunning      = errors.New("Too many submissions are already running, please retry later")
+
+	ErrInvalidAttachmentName = errors.New("Attachment name must be made of letters, digits, dots, dashes and underscores")
+	ErrAttachmentTooLarge    = errors.New("Attachment is too large")
+	ErrUnsupportedAttachment = errors.New("Attachment must be a PNG, JPEG, GIF or WebP image, a PDF or a text file")
+	ErrAttachmentExists      = errors.New("Attachment already exists")
+	ErrAttachmentNotFound    = errors.New("Attachment not found")
+)
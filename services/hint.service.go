@@ -0,0 +1,161 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var hintProgressCollection *mongo.Collection
+
+// initHints sets up the collection recording the hints revealed by each user.
+func initHints() {
+	hintProgressCollection = database.Collection("hintProgress")
+	_, err := hintProgressCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "questionId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// RedactQuestion hides the hints and editorial of a question from the users who can't manage it, so that solvers
+// only see them through RevealNextHint and GetEditorial. The number of hints is always shown.
+func RedactQuestion(user *models.User, question *models.Question) {
+	question.HintCount = len(question.Hints)
+	if !CanManageQuestion(user, question) {
+		question.Hints = nil
+		question.Editorial = nil
+	}
+}
+
+// validateHints checks that hints are not empty and that the reference solutions of the editorial are in supported languages.
+func validateHints(question models.Question) error {
+	for _, hint := range question.Hints {
+		if hint == "" {
+			return ErrInvalidQuestion
+		}
+	}
+	if question.Editorial != nil {
+		for language := range question.Editorial.Solutions {
+			if _, ok := sandboxPools[language]; !ok {
+				return ErrUnsupportedLanguage
+			}
+		}
+	}
+	return nil
+}
+
+// GetRevealedHints returns the hints of a question that a user revealed so far.
+func GetRevealedHints(user *models.User, id string) (*models.RevealedHints, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := getHintProgress(user, question)
+	if err != nil {
+		return nil, err
+	}
+
+	revealed := min(progress.HintsRevealed, len(question.Hints))
+	if CanManageQuestion(user, question) {
+		revealed = len(question.Hints)
+	}
+	return &models.RevealedHints{Hints: append([]string{}, question.Hints[:revealed]...), Total: len(question.Hints)}, nil
+}
+
+// RevealNextHint reveals the next hint of a question to a user and returns the hints revealed so far.
+// It returns ErrNoMoreHints once every hint is revealed.
+func RevealNextHint(user *models.User, id string) (*models.RevealedHints, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(question.Hints) == 0 {
+		return nil, ErrNoMoreHints
+	}
+
+	// The filter only matches while hints remain, and the upsert then fails on the unique index instead of
+	// revealing a hint past the last one, which keeps concurrent reveals consistent.
+	var progress models.HintProgress
+	err = hintProgressCollection.FindOneAndUpdate(context.Background(),
+		bson.M{"userId": user.ID, "questionId": question.ID, "hintsRevealed": bson.M{"$lt": len(question.Hints)}},
+		bson.M{"$inc": bson.M{"hintsRevealed": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&progress)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrNoMoreHints
+		}
+		return nil, err
+	}
+	return &models.RevealedHints{Hints: append([]string{}, question.Hints[:progress.HintsRevealed]...), Total: len(question.Hints)}, nil
+}
+
+// GiveUp records that a user gave up on a question, which unlocks its editorial for them, and returns the editorial.
+func GiveUp(user *models.User, id string) (*models.Editorial, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if question.Editorial == nil {
+		return nil, ErrEditorialNotFound
+	}
+
+	_, err = hintProgressCollection.UpdateOne(context.Background(),
+		bson.M{"userId": user.ID, "questionId": question.ID},
+		bson.M{"$min": bson.M{"gaveUpAt": time.Now()}, "$setOnInsert": bson.M{"hintsRevealed": 0}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return question.Editorial, nil
+}
+
+// GetEditorial returns the editorial of a question to a user who solved the question or gave up on it.
+// It returns ErrEditorialLocked to the other users, unless they manage the question.
+func GetEditorial(user *models.User, id string) (*models.Editorial, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if question.Editorial == nil {
+		return nil, ErrEditorialNotFound
+	}
+	if CanManageQuestion(user, question) {
+		return question.Editorial, nil
+	}
+
+	progress, err := getHintProgress(user, question)
+	if err != nil {
+		return nil, err
+	}
+	if progress.GaveUpAt != nil {
+		return question.Editorial, nil
+	}
+	accepted, err := submissionCollection.CountDocuments(context.Background(),
+		bson.M{"userId": user.ID, "questionId": question.ID, "accepted": true}, options.Count().SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if accepted == 0 {
+		return nil, ErrEditorialLocked
+	}
+	return question.Editorial, nil
+}
+
+// getHintProgress returns the hint progress of a user on a question, which is empty before their first hint.
+func getHintProgress(user *models.User, question *models.Question) (*models.HintProgress, error) {
+	progress := models.HintProgress{UserID: user.ID, QuestionID: question.ID}
+	err := hintProgressCollection.FindOne(context.Background(),
+		bson.M{"userId": user.ID, "questionId": question.ID}).Decode(&progress)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	return &progress, nil
+}
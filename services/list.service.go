@@ -0,0 +1,241 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxListQuestions is the maximum number of questions in a problem list.
+const maxListQuestions = 500
+
+var listCollection *mongo.Collection
+
+// initLists sets up the problem lists collection.
+func initLists() {
+	listCollection = database.Collection("lists")
+	_, err := listCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ownerId", Value: 1}}},
+		{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "name", Value: 1}}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CanViewList reports whether a user may see a problem list: every user sees shared and public lists, and private lists
+// are only seen by their owner and admins.
+func CanViewList(user *models.User, list *models.ProblemList) bool {
+	return list.Visibility != models.VisibilityPrivate || CanManageList(user, list)
+}
+
+// CanManageList reports whether a user may edit or delete a problem list: its owner and admins.
+func CanManageList(user *models.User, list *models.ProblemList) bool {
+	return user.Role == models.RoleAdmin || list.OwnerID == user.ID
+}
+
+// GetLists returns the public lists and the lists of a user, sorted by name. With onlyOwned, only the lists of the user are returned.
+func GetLists(userID primitive.ObjectID, onlyOwned bool) ([]models.ProblemList, error) {
+	filter := bson.M{"ownerId": userID}
+	if !onlyOwned {
+		filter = bson.M{"$or": bson.A{filter, bson.M{"visibility": models.VisibilityPublic}}}
+	}
+
+	cursor, err := listCollection.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	lists := []models.ProblemList{}
+	if err := cursor.All(context.Background(), &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// GetListByID retrieves a problem list by its ID. It returns ErrListNotFound when there is no such list.
+func GetListByID(id string) (*models.ProblemList, error) {
+	listID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrListNotFound
+	}
+
+	var list models.ProblemList
+	err = listCollection.FindOne(context.Background(), bson.M{"_id": listID}).Decode(&list)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetListDetail returns a problem list with its questions in order and the progress of a user on them.
+func GetListDetail(user *models.User, list *models.ProblemList) (*models.ProblemListDetail, error) {
+	cursor, err := questionCollection.Find(context.Background(),
+		bson.M{"_id": bson.M{"$in": list.QuestionIDs}, "deletedAt": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"title": 1, "level": 1, "tags": 1, "languages": 1, "authorId": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var found []models.QuestionSummary
+	if err := cursor.All(context.Background(), &found); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.QuestionSummary, len(found))
+	for _, question := range found {
+		byID[question.ID] = question
+	}
+
+	statuses, err := GetQuestionStatuses(user.ID, list.QuestionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := models.ProblemListDetail{ProblemList: *list, Questions: []models.QuestionSummary{}}
+	for _, id := range list.QuestionIDs {
+		question, ok := byID[id]
+		if !ok {
+			continue
+		}
+		question.Difficulty = models.Difficulties[question.Level]
+		question.Status = statuses[id]
+		switch question.Status {
+		case models.StatusSolved:
+			detail.Progress.Solved++
+		case models.StatusAttempted:
+			detail.Progress.Attempted++
+		}
+		detail.Questions = append(detail.Questions, question)
+	}
+	detail.Progress.Total = len(detail.Questions)
+	return &detail, nil
+}
+
+// CreateList creates a problem list owned by a user.
+func CreateList(ownerID primitive.ObjectID, list models.ProblemList) (*models.ProblemList, error) {
+	if err := validateList(&list, nil); err != nil {
+		return nil, err
+	}
+	list.ID = primitive.NilObjectID
+	list.OwnerID = ownerID
+	list.CreatedAt = time.Now()
+	list.UpdatedAt = list.CreatedAt
+
+	result, err := listCollection.InsertOne(context.Background(), list)
+	if err != nil {
+		return nil, err
+	}
+	list.ID = result.InsertedID.(primitive.ObjectID)
+	return &list, nil
+}
+
+// UpdateList replaces the name, description, visibility and questions of a problem list. Questions deleted since they
+// were added may stay in the list, where they are hidden until restored.
+func UpdateList(id string, list models.ProblemList) (*models.ProblemList, error) {
+	current, err := GetListByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateList(&list, current.QuestionIDs); err != nil {
+		return nil, err
+	}
+
+	var updated models.ProblemList
+	err = listCollection.FindOneAndUpdate(context.Background(), bson.M{"_id": current.ID},
+		bson.M{"$set": bson.M{
+			"name":        list.Name,
+			"description": list.Description,
+			"visibility":  list.Visibility,
+			"questionIds": list.QuestionIDs,
+			"updatedAt":   time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteList deletes a problem list. It returns ErrListNotFound when there is no such list.
+func DeleteList(id string) error {
+	listID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrListNotFound
+	}
+
+	result, err := listCollection.DeleteOne(context.Background(), bson.M{"_id": listID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrListNotFound
+	}
+	return nil
+}
+
+// validateList checks that a problem list has a name and a valid visibility, defaulting to private, and that its
+// questions appear only once. Questions not in kept must exist and not be in the trash, while the questions in kept
+// are the ones the list already had, which stay even when they were deleted since, so that a list can still be edited.
+func validateList(list *models.ProblemList, kept []primitive.ObjectID) error {
+	if err := checkList(list); err != nil {
+		return err
+	}
+
+	var added []primitive.ObjectID
+	for _, id := range list.QuestionIDs {
+		if !slices.Contains(kept, id) {
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	count, err := questionCollection.CountDocuments(context.Background(),
+		bson.M{"_id": bson.M{"$in": added}, "deletedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	if int(count) != len(added) {
+		return fmt.Errorf("%w: some questions do not exist", ErrInvalidList)
+	}
+	return nil
+}
+
+// checkList normalizes a problem list and checks its name, visibility and size, and that no question appears twice.
+func checkList(list *models.ProblemList) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Visibility == "" {
+		list.Visibility = models.VisibilityPrivate
+	}
+	if list.Name == "" || len(list.QuestionIDs) > maxListQuestions {
+		return ErrInvalidList
+	}
+	if list.Visibility != models.VisibilityPrivate && list.Visibility != models.VisibilityShared && list.Visibility != models.VisibilityPublic {
+		return ErrInvalidList
+	}
+	if list.QuestionIDs == nil {
+		list.QuestionIDs = []primitive.ObjectID{}
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(list.QuestionIDs))
+	for _, id := range list.QuestionIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: question %s appears more than once", ErrInvalidList, id.Hex())
+		}
+		seen[id] = true
+	}
+	return nil
+}
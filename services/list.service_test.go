@@ -0,0 +1,44 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckList(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	tests := []struct {
+		name string
+		list models.ProblemList
+		err  error
+	}{
+		{"valid", models.ProblemList{Name: "Blind 75", Visibility: models.VisibilityPublic, QuestionIDs: []primitive.ObjectID{a, b}}, nil},
+		{"default visibility", models.ProblemList{Name: "Graphs"}, nil},
+		{"missing name", models.ProblemList{Name: "  "}, ErrInvalidList},
+		{"unknown visibility", models.ProblemList{Name: "Graphs", Visibility: "friends"}, ErrInvalidList},
+		{"duplicate question", models.ProblemList{Name: "Graphs", QuestionIDs: []primitive.ObjectID{a, b, a}}, ErrInvalidList},
+		{"too many questions", models.ProblemList{Name: "Graphs", QuestionIDs: make([]primitive.ObjectID, maxListQuestions+1)}, ErrInvalidList},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list := test.list
+			err := checkList(&list)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("checkList() error = %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if list.Name != strings.TrimSpace(test.list.Name) || list.QuestionIDs == nil {
+				t.Errorf("checkList() = %+v, want a trimmed name and non-nil questions", list)
+			}
+			if test.list.Visibility == "" && list.Visibility != models.VisibilityPrivate {
+				t.Errorf("checkList() visibility = %q, want %q", list.Visibility, models.VisibilityPrivate)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"bytes"
+	"container/list"
+	"fmt"
+	stdhtml "html"
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// markdown converts question descriptions written in GitHub Flavored Markdown, with $inline$ and $$display$$ LaTeX math,
+// to HTML. Raw HTML is kept and left to descriptionPolicy to sanitize. Math is left for the frontend to typeset,
+// wrapped in the \( \) and \[ \] delimiters understood by KaTeX and MathJax.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithInlineParsers(util.Prioritized(&mathParser{}, 150))),
+	goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(&mathRenderer{}, 500)), html.WithUnsafe()),
+)
+
+// descriptionPolicy removes from the rendered HTML everything that could run scripts or break out of the page,
+// keeping the classes used for math and code highlighting.
+var descriptionPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(math (inline|display)|language-[\w+#-]+)$`)).OnElements("span", "code")
+	return policy
+}()
+
+// descriptionCache holds the rendered descriptions of the most recently read question versions. Versions are immutable,
+// so cached descriptions never go stale.
+var descriptionCache = struct {
+	sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	capacity int
+}{entries: map[string]*list.Element{}, order: list.New()}
+
+type cachedDescription struct {
+	key  string
+	html string
+}
+
+// initMarkdown reads the number of rendered descriptions kept in memory from DESCRIPTION_CACHE_SIZE (default 1000).
+func initMarkdown() {
+	descriptionCache.capacity = envInt("DESCRIPTION_CACHE_SIZE", 1000)
+}
+
+// RenderDescription returns the description of a question version as sanitized HTML.
+func RenderDescription(question models.Question) (string, error) {
+	key := fmt.Sprintf("%s/%d", question.ID.Hex(), question.Version)
+	descriptionCache.Lock()
+	if element, ok := descriptionCache.entries[key]; ok {
+		descriptionCache.order.MoveToFront(element)
+		descriptionCache.Unlock()
+		return element.Value.(*cachedDescription).html, nil
+	}
+	descriptionCache.Unlock()
+
+	var rendered bytes.Buffer
+	if err := markdown.Convert([]byte(question.Description), &rendered); err != nil {
+		return "", err
+	}
+	sanitized := descriptionPolicy.Sanitize(rendered.String())
+
+	descriptionCache.Lock()
+	defer descriptionCache.Unlock()
+	if descriptionCache.capacity == 0 {
+		return sanitized, nil
+	}
+	if _, ok := descriptionCache.entries[key]; !ok {
+		descriptionCache.entries[key] = descriptionCache.order.PushFront(&cachedDescription{key: key, html: sanitized})
+		if descriptionCache.order.Len() > descriptionCache.capacity {
+			oldest := descriptionCache.order.Remove(descriptionCache.order.Back()).(*cachedDescription)
+			delete(descriptionCache.entries, oldest.key)
+		}
+	}
+	return sanitized, nil
+}
+
+var kindMath = ast.NewNodeKind("Math")
+
+// mathNode is a LaTeX formula of a description, displayed on its own line when delimited by $$.
+type mathNode struct {
+	ast.BaseInline
+	formula []byte
+	display bool
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Formula": string(n.formula)}, nil)
+}
+
+// mathParser parses the math delimited by $ or $$ in descriptions. As in Pandoc, an opening $ must be followed by a
+// non-space character and a closing $ preceded by a non-space character and not followed by a digit, so that
+// amounts like $5 are left as text.
+type mathParser struct{}
+
+func (p *mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delimiter := 1
+	if len(line) > 1 && line[1] == '$' {
+		delimiter = 2
+	}
+	if delimiter == 1 && (len(line) < 2 || util.IsSpace(line[1])) {
+		return nil
+	}
+	block.Advance(delimiter)
+
+	var formula []byte
+	for {
+		line, _ := block.PeekLine()
+		if line == nil {
+			return nil
+		}
+		for i := 0; i < len(line); i++ {
+			switch {
+			case line[i] == '\\':
+				i++
+			case line[i] == '$' && isMathClosing(line, i, delimiter):
+				formula = append(formula, line[:i]...)
+				block.Advance(i + delimiter)
+				return &mathNode{formula: bytes.TrimSpace(formula), display: delimiter == 2}
+			}
+		}
+		formula = append(formula, line...)
+		block.AdvanceLine()
+	}
+}
+
+// isMathClosing reports whether the $ at index i of a line closes a formula opened with the given delimiter.
+func isMathClosing(line []byte, i, delimiter int) bool {
+	if delimiter == 2 {
+		return i+1 < len(line) && line[i+1] == '$'
+	}
+	if i == 0 || util.IsSpace(line[i-1]) {
+		return false
+	}
+	return i+1 >= len(line) || line[i+1] < '0' || line[i+1] > '9'
+}
+
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.renderMath)
+}
+
+func (r *mathRenderer) renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	math := node.(*mathNode)
+	formula := stdhtml.EscapeString(string(math.formula))
+	if math.display {
+		fmt.Fprintf(w, `<span class="math display">\[%s\]</span>`, formula)
+	} else {
+		fmt.Fprintf(w, `<span class="math inline">\(%s\)</span>`, formula)
+	}
+	return ast.WalkSkipChildren, nil
+}
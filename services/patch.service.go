@@ -0,0 +1,244 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PatchQuestion applies a JSON Merge Patch (RFC 7386) to the fields of a question listed by models.QuestionFields:
+// fields in the patch replace the stored ones, null removes them and absent fields are left untouched. Unknown fields
+// are rejected. The patched question must still be valid.
+// expectedVersion works as in UpdateQuestion; when it is 0 the patch applies to the version it was computed from.
+func PatchQuestion(id string, editorID primitive.ObjectID, expectedVersion int, patch []byte) (*models.Question, error) {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	if _, ok := patchValue.(map[string]interface{}); !ok {
+		return nil, ErrInvalidPatch
+	}
+
+	return modifyQuestion(id, editorID, expectedVersion, func(question *models.Question) error {
+		return applyPatch(question, patchValue)
+	})
+}
+
+// applyPatch applies a decoded merge patch to the fields of a question.
+func applyPatch(question *models.Question, patch interface{}) error {
+	fields := questionFields(*question)
+	original, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	if err := json.Unmarshal(original, &target); err != nil {
+		return err
+	}
+
+	merged := mergePatch(target, patch)
+	var result models.QuestionFields
+	if err := checkPatchKeys(merged, reflect.TypeOf(result), ""); err != nil {
+		return err
+	}
+	patched, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	// The difficulty can be patched through either field, the other one follows.
+	if result.Difficulty != fields.Difficulty && result.Level == fields.Level {
+		result.Level = 0
+	} else if result.Level != fields.Level && result.Difficulty == fields.Difficulty {
+		result.Difficulty = ""
+	}
+	setQuestionFields(question, result)
+	return nil
+}
+
+// checkPatchKeys checks that every key of a patched document is the JSON key of a field of the type it decodes to.
+// Keys are compared exactly, since encoding/json would otherwise accept "title" for "Title" and silently ignore one of them.
+func checkPatchKeys(value interface{}, typ reflect.Type, path string) error {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		if typ.Kind() == reflect.Slice {
+			for i, item := range value {
+				if err := checkPatchKeys(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = typ.Field(i).Name
+			}
+			fields[name] = typ.Field(i).Type
+		}
+		for key, item := range value {
+			name := key
+			if path != "" {
+				name = path + "." + key
+			}
+			fieldType, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("%w: unknown field %s", ErrInvalidPatch, name)
+			}
+			if err := checkPatchKeys(item, fieldType, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// questionFields returns the fields of a question that patches change.
+func questionFields(question models.Question) models.QuestionFields {
+	return models.QuestionFields{
+		Title:       question.Title,
+		Description: question.Description,
+		Level:       question.Level,
+		Difficulty:  models.Difficulties[question.Level],
+		InputTypes:  question.InputTypes,
+		OutputType:  question.OutputType,
+		Tags:        question.Tags,
+		Languages:   question.Languages,
+		Tests:       question.Tests,
+		Examples:    question.Examples,
+		Hints:       question.Hints,
+		Editorial:   question.Editorial,
+	}
+}
+
+// setQuestionFields replaces the fields of a question that patches change.
+func setQuestionFields(question *models.Question, fields models.QuestionFields) {
+	question.Title = fields.Title
+	question.Description = fields.Description
+	question.Level = fields.Level
+	question.Difficulty = fields.Difficulty
+	question.InputTypes = fields.InputTypes
+	question.OutputType = fields.OutputType
+	question.Tags = fields.Tags
+	question.Languages = fields.Languages
+	question.Hints = fields.Hints
+	question.Editorial = fields.Editorial
+	question.Tests = fields.Tests
+	question.Examples = fields.Examples
+}
+
+// InsertTest inserts a test case at the given index of a question's tests, shifting the following tests.
+// An index equal to the number of tests appends the test.
+func InsertTest(id string, editorID primitive.ObjectID, expectedVersion, index int, test models.Test) (*models.Question, error) {
+	return modifyQuestion(id, editorID, expectedVersion, func(question *models.Question) error {
+		return insertTest(question, index, test)
+	})
+}
+
+// ReplaceTest replaces the test case at the given index of a question's tests.
+func ReplaceTest(id string, editorID primitive.ObjectID, expectedVersion, index int, test models.Test) (*models.Question, error) {
+	return modifyQuestion(id, editorID, expectedVersion, func(question *models.Question) error {
+		if index < 0 || index >= len(question.Tests) {
+			return ErrTestNotFound
+		}
+		question.Tests[index] = test
+		return nil
+	})
+}
+
+// DeleteTest removes the test case at the given index of a question's tests. The last test of a question can't be removed,
+// nor a test linked to an example.
+func DeleteTest(id string, editorID primitive.ObjectID, expectedVersion, index int) (*models.Question, error) {
+	return modifyQuestion(id, editorID, expectedVersion, func(question *models.Question) error {
+		return deleteTest(question, index)
+	})
+}
+
+// insertTest inserts a test case in a question and moves the links of its examples to the tests that were shifted.
+func insertTest(question *models.Question, index int, test models.Test) error {
+	if index < 0 || index > len(question.Tests) {
+		return ErrTestNotFound
+	}
+	question.Tests = slices.Insert(question.Tests, index, test)
+	for _, example := range question.Examples {
+		if example.TestIndex != nil && *example.TestIndex >= index {
+			*example.TestIndex++
+		}
+	}
+	return nil
+}
+
+// deleteTest removes a test case that no example is linked to from a question, and moves the links of its examples to
+// the tests that were shifted.
+func deleteTest(question *models.Question, index int) error {
+	if index < 0 || index >= len(question.Tests) {
+		return ErrTestNotFound
+	}
+	for i, example := range question.Examples {
+		if example.TestIndex != nil && *example.TestIndex == index {
+			return fmt.Errorf("%w: test %d is linked to example %d, unlink it first", ErrInvalidExample, index, i+1)
+		}
+	}
+	question.Tests = slices.Delete(question.Tests, index, index+1)
+	for _, example := range question.Examples {
+		if example.TestIndex != nil && *example.TestIndex > index {
+			*example.TestIndex--
+		}
+	}
+	return nil
+}
+
+// modifyQuestion loads a question, applies a change to it and saves the result as a new version.
+// The save is conditional on the loaded version so that concurrent changes are not lost.
+func modifyQuestion(id string, editorID primitive.ObjectID, expectedVersion int, change func(*models.Question) error) (*models.Question, error) {
+	question, err := GetQuestionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && question.Version != expectedVersion {
+		return nil, ErrVersionConflict
+	}
+
+	if err := change(question); err != nil {
+		return nil, err
+	}
+	return UpdateQuestion(id, editorID, question.Version, *question)
+}
+
+// mergePatch applies a JSON Merge Patch to a decoded JSON document as described in RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7386, appendix A.
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.target+" "+test.patch, func(t *testing.T) {
+			var target, patch, want interface{}
+			for value, document := range map[*interface{}]string{&target: test.target, &patch: test.patch, &want: test.want} {
+				if err := json.Unmarshal([]byte(document), value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	question := func() models.Question {
+		return models.Question{
+			Title:       "Two Sum",
+			Description: "Find two numbers.",
+			Level:       models.LevelEasy,
+			InputTypes:  "int[],int",
+			OutputType:  "int[]",
+			Tags:        []string{"array"},
+			Tests:       []models.Test{{Input: "[1,2],3", ExpectedOutput: "[0,1]"}},
+			Examples:    []models.Example{{Input: "[1,2],3", Output: "[0,1]"}},
+			Hints:       []string{"Use a map."},
+			Editorial:   &models.Editorial{Approach: "Hash map", Solutions: map[string]string{"python": "pass"}},
+			Version:     3,
+		}
+	}
+	tests := []struct {
+		name   string
+		patch  string
+		modify func(*models.Question)
+		err    error
+	}{
+		{"title", `{"Title":"Three Sum"}`, func(q *models.Question) { q.Title = "Three Sum" }, nil},
+		{"clear title", `{"Title":null}`, func(q *models.Question) { q.Title = "" }, nil},
+		{"tags", `{"Tags":["hash-table"]}`, func(q *models.Question) { q.Tags = []string{"hash-table"} }, nil},
+		{"clear tags", `{"Tags":null}`, func(q *models.Question) { q.Tags = nil }, nil},
+		{"tests", `{"Tests":[{"Input":"1","ExpectedOutput":"2"}]}`, func(q *models.Question) {
+			q.Tests = []models.Test{{Input: "1", ExpectedOutput: "2"}}
+		}, nil},
+		{"editorial approach", `{"Editorial":{"approach":"Sort"}}`, func(q *models.Question) {
+			q.Editorial = &models.Editorial{Approach: "Sort", Solutions: map[string]string{"python": "pass"}}
+		}, nil},
+		{"editorial solution removed", `{"Editorial":{"solutions":{"python":null}}}`, func(q *models.Question) {
+			q.Editorial = &models.Editorial{Approach: "Hash map", Solutions: map[string]string{}}
+		}, nil},
+		{"level", `{"Level":3}`, func(q *models.Question) {
+			q.Level = models.LevelHard
+			q.Difficulty = ""
+		}, nil},
+		{"difficulty", `{"Difficulty":"Medium"}`, func(q *models.Question) {
+			q.Level = 0
+			q.Difficulty = "Medium"
+		}, nil},
+		{"level and difficulty", `{"Level":3,"Difficulty":"Hard"}`, func(q *models.Question) {
+			q.Level = models.LevelHard
+			q.Difficulty = "Hard"
+		}, nil},
+		{"empty", `{}`, func(q *models.Question) {}, nil},
+		{"unknown field", `{"title":"Three Sum"}`, nil, ErrInvalidPatch},
+		{"computed field", `{"DescriptionHTML":"<p></p>"}`, nil, ErrInvalidPatch},
+		{"stored field", `{"AuthorID":"000000000000000000000000"}`, nil, ErrInvalidPatch},
+		{"unknown nested field", `{"Tests":[{"input":"1"}]}`, nil, ErrInvalidPatch},
+		{"wrong type", `{"Level":"easy"}`, nil, ErrInvalidPatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var patch interface{}
+			if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			got := question()
+			err := applyPatch(&got, patch)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("applyPatch() error = %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyPatch() error = %v", err)
+			}
+
+			want := question()
+			want.Difficulty = models.Difficulties[want.Level]
+			test.modify(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyPatch() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestInsertTest(t *testing.T) {
+	tests := []struct {
+		name        string
+		index       int
+		err         error
+		tests       []string
+		testIndexes []int
+	}{
+		{"first", 0, nil, []string{"new", "a", "b", "c"}, []int{1, 3}},
+		{"middle", 1, nil, []string{"a", "new", "b", "c"}, []int{0, 3}},
+		{"before linked", 2, nil, []string{"a", "b", "new", "c"}, []int{0, 3}},
+		{"append", 3, nil, []string{"a", "b", "c", "new"}, []int{0, 2}},
+		{"negative", -1, ErrTestNotFound, nil, nil},
+		{"past the end", 4, ErrTestNotFound, nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			question := linkedQuestion()
+			err := insertTest(&question, test.index, models.Test{Input: "new"})
+			checkTestChange(t, question, err, test.err, test.tests, test.testIndexes)
+		})
+	}
+}
+
+func TestDeleteTest(t *testing.T) {
+	tests := []struct {
+		name        string
+		index       int
+		err         error
+		tests       []string
+		testIndexes []int
+	}{
+		{"before links", 1, nil, []string{"a", "c"}, []int{0, 1}},
+		{"linked first", 0, ErrInvalidExample, nil, nil},
+		{"linked last", 2, ErrInvalidExample, nil, nil},
+		{"negative", -1, ErrTestNotFound, nil, nil},
+		{"past the end", 3, ErrTestNotFound, nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			question := linkedQuestion()
+			err := deleteTest(&question, test.index)
+			checkTestChange(t, question, err, test.err, test.tests, test.testIndexes)
+		})
+	}
+}
+
+// linkedQuestion returns a question with the tests a, b and c, and examples linked to a and c around an unlinked one.
+func linkedQuestion() models.Question {
+	return models.Question{
+		Tests: []models.Test{{Input: "a"}, {Input: "b"}, {Input: "c"}},
+		Examples: []models.Example{
+			{Input: "a", TestIndex: intPointer(0)},
+			{Input: "x"},
+			{Input: "c", TestIndex: intPointer(2)},
+		},
+	}
+}
+
+func checkTestChange(t *testing.T, question models.Question, err, wantErr error, tests []string, testIndexes []int) {
+	t.Helper()
+	if wantErr != nil {
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("error = %v, want %v", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var inputs []string
+	for _, test := range question.Tests {
+		inputs = append(inputs, test.Input)
+	}
+	if !reflect.DeepEqual(inputs, tests) {
+		t.Errorf("tests = %v, want %v", inputs, tests)
+	}
+	linked := []int{*question.Examples[0].TestIndex, *question.Examples[2].TestIndex}
+	if !reflect.DeepEqual(linked, testIndexes) || question.Examples[1].TestIndex != nil {
+		t.Errorf("example links = %v, %v, want %v", linked, question.Examples[1].TestIndex, testIndexes)
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// poolRetryDelay is how long a pool waits before trying again after failing to start a warm pod.
+const poolRetryDelay = 10 * time.Second
+
+// podPool keeps a number of running sandbox pods for a language so that submissions don't wait for a pod to start.
+// Pods are used by a single test and deleted afterwards, and the pool starts a replacement in the background.
+type podPool struct {
+	language      string
+	containerName string
+	writableDirs  []string
+	size          int
+	ready         chan string
+	refill        chan struct{}
+	inUse         atomic.Int64
+	created       atomic.Int64
+	coldStarts    atomic.Int64
+}
+
+// sandboxInstance identifies this server among the replicas sharing the sandbox namespace. It labels the pods started by
+// this server so that it only cleans up its own pods. It is the hostname, which is the pod name when the server runs in
+// Kubernetes and stays the same when its container restarts.
+var sandboxInstance string
+
+var sandboxPools = map[string]*podPool{
+	"java":   {language: "java", containerName: "java-test", writableDirs: []string{"/app/src", "/app/target", "/tmp"}},
+	"python": {language: "python", containerName: "python-test", writableDirs: []string{"/app/my_tests", "/tmp"}},
+}
+
+// initPools sizes the warm pool of each language from the sandbox configuration and starts filling the pools.
+func initPools() {
+	sandboxInstance = instanceID()
+	enabled := false
+	for language, pool := range sandboxPools {
+		pool.size = *sandbox.Languages[language].PoolSize
+		pool.ready = make(chan string, pool.size)
+		pool.refill = make(chan struct{}, 1)
+		enabled = enabled || pool.size > 0
+	}
+	if !enabled {
+		return
+	}
+
+	if _, err := kubeClient(); err != nil {
+		log.Printf("warm sandbox pools disabled: %v", err)
+		return
+	}
+	for _, pool := range sandboxPools {
+		// Pods left over by a previous run of this server are not tracked by the new pools. The pods of the other
+		// replicas are left alone, since they may be running submissions.
+		err := kubeClientset.CoreV1().Pods(sandbox.Namespace).DeleteCollection(context.TODO(), metav1.DeleteOptions{},
+			metav1.ListOptions{LabelSelector: "app=" + sandboxLabel + ",pool=" + pool.language + ",instance=" + sandboxInstance})
+		if err != nil {
+			log.Printf("failed to clean up %s sandbox pods: %v", pool.language, err)
+		}
+		go pool.fill()
+		pool.requestRefill()
+	}
+}
+
+// instanceID returns the hostname of the server, or a random ID when the hostname can't be used as a label value.
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" || len(validation.IsValidLabelValue(hostname)) > 0 {
+		return uuid.New().String()
+	}
+	return hostname
+}
+
+// GetPoolStats returns the size and usage counters of every warm pool.
+func GetPoolStats() []models.PoolStats {
+	var stats []models.PoolStats
+	for _, pool := range sandboxPools {
+		stats = append(stats, models.PoolStats{
+			Language:   pool.language,
+			Size:       pool.size,
+			Ready:      len(pool.ready),
+			InUse:      pool.inUse.Load(),
+			Created:    pool.created.Load(),
+			ColdStarts: pool.coldStarts.Load(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Language < stats[j].Language })
+	return stats
+}
+
+// checkout takes a running pod from the pool, or starts a new one when the pool is empty.
+// The pod must be handed back with release.
+func (p *podPool) checkout() (string, error) {
+	for {
+		select {
+		case podName := <-p.ready:
+			p.requestRefill()
+			if !p.isRunning(podName) {
+				deletePod(podName)
+				continue
+			}
+			p.inUse.Add(1)
+			return podName, nil
+		default:
+		}
+
+		p.coldStarts.Add(1)
+		podName, err := p.startPod()
+		if err != nil {
+			return "", err
+		}
+		p.inUse.Add(1)
+		return podName, nil
+	}
+}
+
+// release deletes a pod returned by checkout. Pods are never reused since they ran untrusted code.
+func (p *podPool) release(podName string) {
+	p.inUse.Add(-1)
+	deletePod(podName)
+}
+
+// requestRefill wakes up the goroutine that tops up the pool, unless a refill is already pending.
+func (p *podPool) requestRefill() {
+	if p.size == 0 {
+		return
+	}
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// fill starts pods until the pool is full every time a refill is requested. It is the only writer of the ready channel.
+func (p *podPool) fill() {
+	for range p.refill {
+		for len(p.ready) < p.size {
+			podName, err := p.startPod()
+			if err != nil {
+				log.Printf("failed to start warm %s pod: %v", p.language, err)
+				time.Sleep(poolRetryDelay)
+				continue
+			}
+			p.ready <- podName
+		}
+	}
+}
+
+// startPod creates a sandbox pod for the pool's language and waits for it to be running.
+func (p *podPool) startPod() (string, error) {
+	clientset, err := kubeClient()
+	if err != nil {
+		return "", err
+	}
+
+	podName := p.language + "-test-pod" + uuid.New().String()
+	pod := sandboxPod(podName, p.containerName, sandbox.Languages[p.language], p.writableDirs...)
+	pod.Labels["pool"] = p.language
+	pod.Labels["instance"] = sandboxInstance
+
+	_, err = clientset.CoreV1().Pods(sandbox.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("%w - failed to create pod: %w", errSandbox, err)
+	}
+	p.created.Add(1)
+
+	if err := waitForPodRunning(podName); err != nil {
+		deletePod(podName)
+		return "", err
+	}
+	return podName, nil
+}
+
+// isRunning checks that a pod which waited in the pool has not been evicted or stopped in the meantime.
+func (p *podPool) isRunning(podName string) bool {
+	pod, err := kubeClientset.CoreV1().Pods(sandbox.Namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	return err == nil && pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil
+}
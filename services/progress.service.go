@@ -0,0 +1,153 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const dayFormat = "2006-01-02"
+
+// GetQuestionStatuses returns whether a user solved or only attempted each of the given questions. Questions the user
+// never submitted to are left out.
+func GetQuestionStatuses(userID primitive.ObjectID, questionIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	return questionStatuses(bson.M{"userId": userID, "questionId": bson.M{"$in": questionIDs}})
+}
+
+// questionStatuses returns the status of every question of the submissions matching the filter.
+func questionStatuses(filter bson.M) (map[primitive.ObjectID]string, error) {
+	cursor, err := submissionCollection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": "$questionId", "solved": bson.M{"$max": "$accepted"}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		QuestionID primitive.ObjectID `bson:"_id"`
+		Solved     bool               `bson:"solved"`
+	}
+	if err := cursor.All(context.Background(), &results); err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[primitive.ObjectID]string, len(results))
+	for _, result := range results {
+		statuses[result.QuestionID] = models.StatusAttempted
+		if result.Solved {
+			statuses[result.QuestionID] = models.StatusSolved
+		}
+	}
+	return statuses, nil
+}
+
+// GetProgress summarizes the submissions of a user: the questions they solved and attempted, the solved questions by
+// difficulty and tag, and their activity over the last days, with days and streaks counted in the given time zone.
+func GetProgress(userID primitive.ObjectID, location *time.Location, days int) (*models.Progress, error) {
+	statuses, err := questionStatuses(bson.M{"userId": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := models.Progress{
+		Solved:             []primitive.ObjectID{},
+		Attempted:          []primitive.ObjectID{},
+		SolvedByDifficulty: map[string]int{},
+		SolvedByTag:        map[string]int{},
+		Calendar:           []models.ActivityDay{},
+	}
+	for questionID, status := range statuses {
+		if status == models.StatusSolved {
+			progress.Solved = append(progress.Solved, questionID)
+		} else {
+			progress.Attempted = append(progress.Attempted, questionID)
+		}
+	}
+
+	cursor, err := questionCollection.Find(context.Background(),
+		bson.M{"_id": bson.M{"$in": progress.Solved}, "deletedAt": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"level": 1, "tags": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var solved []models.QuestionSummary
+	if err := cursor.All(context.Background(), &solved); err != nil {
+		return nil, err
+	}
+	for _, question := range solved {
+		if difficulty, ok := models.Difficulties[question.Level]; ok {
+			progress.SolvedByDifficulty[difficulty]++
+		}
+		for _, tag := range question.Tags {
+			progress.SolvedByTag[tag]++
+		}
+	}
+
+	activity, err := getActivity(userID, location)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().In(location)
+	since := today.AddDate(0, 0, -days+1).Format(dayFormat)
+	for _, day := range activity {
+		if day.Date >= since {
+			progress.Calendar = append(progress.Calendar, day)
+		}
+	}
+	progress.CurrentStreak, progress.LongestStreak = streaks(activity, today)
+	return &progress, nil
+}
+
+// getActivity counts the submissions of a user by day, oldest first.
+func getActivity(userID primitive.ObjectID, location *time.Location) ([]models.ActivityDay, error) {
+	cursor, err := submissionCollection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt", "timezone": location.String()}},
+			"submissions": bson.M{"$sum": 1},
+			"accepted":    bson.M{"$sum": bson.M{"$cond": bson.A{"$accepted", 1, 0}}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	activity := []models.ActivityDay{}
+	if err := cursor.All(context.Background(), &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// streaks returns the current and the longest runs of consecutive active days. The current streak still counts
+// when today has no activity yet, as long as yesterday had some.
+func streaks(activity []models.ActivityDay, today time.Time) (int, int) {
+	current, longest, run := 0, 0, 0
+	var previous time.Time
+	for _, day := range activity {
+		date, err := time.Parse(dayFormat, day.Date)
+		if err != nil {
+			continue
+		}
+		if run > 0 && date.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		previous = date
+	}
+
+	if len(activity) > 0 {
+		last := activity[len(activity)-1].Date
+		if last == today.Format(dayFormat) || last == today.AddDate(0, 0, -1).Format(dayFormat) {
+			current = run
+		}
+	}
+	return current, longest
+}
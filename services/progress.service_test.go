@@ -0,0 +1,38 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"testing"
+	"time"
+)
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2024, time.March, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		days    []string
+		current int
+		longest int
+	}{
+		{"no activity", nil, 0, 0},
+		{"today only", []string{"2024-03-10"}, 1, 1},
+		{"ending today", []string{"2024-03-07", "2024-03-08", "2024-03-09", "2024-03-10"}, 4, 4},
+		{"ending yesterday", []string{"2024-03-08", "2024-03-09"}, 2, 2},
+		{"stale", []string{"2024-03-01", "2024-03-02", "2024-03-03"}, 0, 3},
+		{"gap", []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-09", "2024-03-10"}, 2, 3},
+		{"across months", []string{"2024-02-28", "2024-02-29", "2024-03-01"}, 0, 3},
+		{"unparsable day", []string{"2024-03-08", "invalid", "2024-03-09"}, 2, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var activity []models.ActivityDay
+			for _, day := range test.days {
+				activity = append(activity, models.ActivityDay{Date: day})
+			}
+			current, longest := streaks(activity, today)
+			if current != test.current || longest != test.longest {
+				t.Errorf("streaks() = %d, %d, want %d, %d", current, longest, test.current, test.longest)
+			}
+		})
+	}
+}
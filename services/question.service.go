@@ -4,18 +4,22 @@ import (
 	"LeetCode-server/models"
 	"context"
 	"fmt"
-	"log"
-	"os"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"os"
+	"strings"
+	"time"
 )
 
+var database *mongo.Database
 var questionCollection *mongo.Collection
 
-// Init initializes the database connection using environment variables and sets up the questionCollection.
+// Init initializes the database connection using environment variables and sets up the questionCollection
+// along with the other collections used by the services.
 func Init() {
 	godotenv.Load()
 	dbUrl := os.Getenv("DATABASE_URL")
@@ -31,108 +35,359 @@ func Init() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	questionCollection = client.Database(dbName).Collection(dbCollection)
+	database = client.Database(dbName)
+	questionCollection = database.Collection(dbCollection)
+	_, err = questionCollection.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "level", Value: 1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "title", Value: 1}}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := initSearch(); err != nil {
+		log.Fatal(err)
+	}
+	initSubmissions()
+	initTags()
+	initHints()
+	initTestData()
+	initMarkdown()
+	initAttachments()
+	initVersions()
+	if err := migrateLegacyLevels(); err != nil {
+		log.Fatal(err)
+	}
+	initTrash()
+	initLists()
+	initUsers()
+	initQuotas()
+	initSandboxConfig()
+	initPools()
 }
 
-// CreateQuestion inserts a new question into the database. It requires a title, description, level, tests, input types, and output type.
+// CreateQuestion inserts a new question into the database on behalf of its author. It requires a title, description, difficulty, and at least one test.
+// The difficulty is given either as a Level or as a Difficulty name.
 // It returns the result of the insertion and any errors encountered.
-func CreateQuestion(title, description string, level int, tests []models.Test, inputTypes string, outputType string) (*mongo.InsertOneResult, error) {
-	if(title == "" || description == "" ||level==0 ||len(tests) == 0){
-		return nil, fmt.Errorf("Question must contain title & description & level & at least one test")
+func CreateQuestion(authorID primitive.ObjectID, question models.Question) (*mongo.InsertOneResult, error) {
+	question = writableQuestion(question)
+	if err := resolveDifficulty(&question); err != nil {
+		return nil, err
 	}
-	question := models.Question{
-			Title:       title,
-			Description: description,
-			Level:       level,
-			Tests:       tests,
-			InputTypes: inputTypes,
-			OutputType: outputType,
+	if err := validateQuestion(question); err != nil {
+		return nil, err
 	}
+	tags, err := normalizeQuestionTags(question.Tags)
+	if err != nil {
+		return nil, err
+	}
+	question.Tags = tags
+	if question.Tests, err = storeLargeTests(primitive.NilObjectID, question.Tests); err != nil {
+		return nil, err
+	}
+	question.AuthorID = authorID
+	question.Version = 1
 
 	result, err := questionCollection.InsertOne(context.Background(), question)
 	if err != nil {
-			return nil, err
+		return nil, err
+	}
+
+	question.ID = result.InsertedID.(primitive.ObjectID)
+	if err := saveQuestionVersion(question, authorID); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
-// GetQuestionByID retrieves a question by its ID. It returns the question object and any errors encountered.
+// writableQuestion returns a question with only the fields written by clients, leaving out those managed by the server
+// (ID, author, version and deletion) and those computed when a question is read.
+func writableQuestion(question models.Question) models.Question {
+	return models.Question{
+		Title:       question.Title,
+		Description: question.Description,
+		Level:       question.Level,
+		Difficulty:  question.Difficulty,
+		Tests:       question.Tests,
+		Examples:    question.Examples,
+		Hints:       question.Hints,
+		Editorial:   question.Editorial,
+		InputTypes:  question.InputTypes,
+		OutputType:  question.OutputType,
+		Tags:        question.Tags,
+		Languages:   question.Languages,
+	}
+}
+
+// GetQuestionByID retrieves a question by its ID. Deleted questions are not found. It returns the question object and any errors encountered.
 func GetQuestionByID(id string) (*models.Question, error) {
+	return findQuestion(id, bson.M{"deletedAt": bson.M{"$exists": false}})
+}
+
+// GetDeletedQuestionByID retrieves a question in the trash by its ID. It returns the question object and any errors encountered.
+func GetDeletedQuestionByID(id string) (*models.Question, error) {
+	return findQuestion(id, bson.M{"deletedAt": bson.M{"$exists": true}})
+}
+
+// findQuestion retrieves a question by its ID if it also matches the given filter.
+func findQuestion(id string, filter bson.M) (*models.Question, error) {
 	questionID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-			return nil, err
+		return nil, ErrQuestionNotFound
 	}
+	filter["_id"] = questionID
 
 	var question models.Question
-	err = questionCollection.FindOne(context.Background(), bson.M{"_id": questionID}).Decode(&question)
+	err = questionCollection.FindOne(context.Background(), filter).Decode(&question)
 	if err != nil {
-			return nil, err
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
 	}
 	return &question, nil
 }
 
-// GetAllQuestions retrieves all questions from the database and returns them in a slice. It returns any errors encountered during the operation.
+// CanManageQuestion reports whether a user may edit or delete a question: admins manage every question and authors only their own.
+func CanManageQuestion(user *models.User, question *models.Question) bool {
+	switch user.Role {
+	case models.RoleAdmin:
+		return true
+	case models.RoleAuthor:
+		return question.AuthorID == user.ID
+	default:
+		return false
+	}
+}
+
+// GetAllQuestions retrieves all questions that are not deleted from the database, oldest first, and returns them in a slice. It returns any errors encountered during the operation.
 func GetAllQuestions() ([]models.Question, error) {
-	cursor, err := questionCollection.Find(context.Background(), bson.M{})
+	cursor, err := questionCollection.Find(context.Background(), bson.M{"deletedAt": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.M{"_id": 1}))
 	if err != nil {
-			return nil, err
+		return nil, err
 	}
 	defer cursor.Close(context.Background())
 
 	var questions []models.Question
 	for cursor.Next(context.Background()) {
-			var question models.Question
-			if err := cursor.Decode(&question); err != nil {
-					return nil, err
-			}
-			questions = append(questions, question)
+		var question models.Question
+		if err := cursor.Decode(&question); err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
 	}
 
 	if err := cursor.Err(); err != nil {
-			return nil, err
+		return nil, err
 	}
 
 	return questions, nil
 }
 
-// UpdateQuestion updates an existing question based on the provided ID. It updates the question's title, description, level, tests, input types, and output type.
-// It returns the result of the update operation and any errors encountered.
-func UpdateQuestion(id string, title, description string, level int, tests []models.Test, inputTypes string, outputType string) (*mongo.UpdateResult, error) {
+// questionSortFields maps the sort keys accepted by ListQuestions to document fields. Object IDs embed their creation
+// time, so sorting by _id sorts by creation date.
+var questionSortFields = map[string]string{
+	"title":   "title",
+	"level":   "level",
+	"created": "_id",
+}
+
+// ListQuestions returns one page of question summaries matching the filter, along with the total number of matches.
+// Questions can be filtered by level, tag and supported language, and sorted by title, level or creation date, descending when the key starts with "-".
+func ListQuestions(filter models.QuestionFilter) (*models.QuestionPage, error) {
+	query := bson.M{"deletedAt": bson.M{"$exists": false}}
+	if filter.Level != 0 {
+		query["level"] = filter.Level
+	}
+	if filter.Tag != "" {
+		query["tags"] = NormalizeTag(filter.Tag)
+	}
+	if filter.Language != "" {
+		// Questions without a list of languages support every language.
+		query["$or"] = bson.A{
+			bson.M{"languages": filter.Language},
+			bson.M{"languages": bson.M{"$exists": false}},
+			bson.M{"languages": bson.A{}},
+		}
+	}
+
+	direction := 1
+	sortKey := strings.TrimPrefix(filter.Sort, "-")
+	if sortKey != filter.Sort {
+		direction = -1
+	}
+	sortField, ok := questionSortFields[sortKey]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: 1})
+	}
+
+	total, err := questionCollection.CountDocuments(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(sort).
+		SetSkip(int64((filter.Page - 1) * filter.PageSize)).
+		SetLimit(int64(filter.PageSize)).
+		SetProjection(bson.M{"title": 1, "level": 1, "tags": 1, "languages": 1, "authorId": 1})
+	cursor, err := questionCollection.Find(context.Background(), query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	page := models.QuestionPage{Questions: []models.QuestionSummary{}, Total: total, Page: filter.Page, PageSize: filter.PageSize}
+	if err := cursor.All(context.Background(), &page.Questions); err != nil {
+		return nil, err
+	}
+	for i := range page.Questions {
+		page.Questions[i].Difficulty = models.Difficulties[page.Questions[i].Level]
+	}
+	return &page, nil
+}
+
+// validateQuestion checks that a question has a title, description, difficulty level and at least one test, only declares supported languages,
+// that its examples match the tests they are linked to and that its hints and editorial are well formed.
+func validateQuestion(question models.Question) error {
+	if question.Title == "" || question.Description == "" || question.Level == 0 || len(question.Tests) == 0 {
+		return ErrInvalidQuestion
+	}
+	if _, ok := models.Difficulties[question.Level]; !ok {
+		return ErrInvalidDifficulty
+	}
+	if err := validateExamples(question); err != nil {
+		return err
+	}
+	if err := validateHints(question); err != nil {
+		return err
+	}
+	return validateLanguages(question.Languages)
+}
+
+// validateExamples checks that every example has an input and an output, and that the examples linked to a test have
+// the input and expected output of that test. Tests stored in GridFS are too large to be shown as examples.
+func validateExamples(question models.Question) error {
+	for i, example := range question.Examples {
+		if example.Input == "" || example.Output == "" {
+			return fmt.Errorf("%w: example %d must have an input and an output", ErrInvalidExample, i+1)
+		}
+		if example.TestIndex == nil {
+			continue
+		}
+		index := *example.TestIndex
+		if index < 0 || index >= len(question.Tests) {
+			return fmt.Errorf("%w: example %d is linked to test %d, which does not exist", ErrInvalidExample, i+1, index)
+		}
+		test := question.Tests[index]
+		if test.InputFile != nil || test.ExpectedOutputFile != nil {
+			return fmt.Errorf("%w: example %d is linked to test %d, which is too large to be an example", ErrInvalidExample, i+1, index)
+		}
+		if strings.TrimSpace(example.Input) != strings.TrimSpace(test.Input) || strings.TrimSpace(example.Output) != strings.TrimSpace(test.ExpectedOutput) {
+			return fmt.Errorf("%w: example %d does not match the input and expected output of test %d", ErrInvalidExample, i+1, index)
+		}
+	}
+	return nil
+}
+
+// validateLanguages checks that every language a question declares can be run by the judge.
+func validateLanguages(languages []string) error {
+	for _, language := range languages {
+		if _, ok := sandboxPools[language]; !ok {
+			return ErrUnsupportedLanguage
+		}
+	}
+	return nil
+}
+
+// UpdateQuestion updates an existing question based on the provided ID. It updates the question's title, description, level, tests, examples, hints, editorial, input types, output type, tags and languages.
+// Every update creates a new version of the question, recorded in its history with the editor who made it.
+// When expectedVersion is not 0 the update only applies if the question is still at that version, and ErrVersionConflict is returned otherwise.
+// It returns the updated question and any errors encountered.
+func UpdateQuestion(id string, editorID primitive.ObjectID, expectedVersion int, question models.Question) (*models.Question, error) {
 	questionID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-			return nil, err
+		return nil, err
+	}
+	if err := resolveDifficulty(&question); err != nil {
+		return nil, err
+	}
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeQuestionTags(question.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := storeLargeTests(questionID, question.Tests)
+	if err != nil {
+		return nil, err
 	}
 
 	update := bson.M{
-			"$set": bson.M{
-					"title":       title,
-					"description": description,
-					"level":       level,
-					"tests":       tests,
-					"inputTypes":  inputTypes,
-					"outputType":  outputType,
-			},
+		"$set": bson.M{
+			"title":       question.Title,
+			"description": question.Description,
+			"level":       question.Level,
+			"tests":       tests,
+			"examples":    question.Examples,
+			"hints":       question.Hints,
+			"editorial":   question.Editorial,
+			"inputTypes":  question.InputTypes,
+			"outputType":  question.OutputType,
+			"tags":        tags,
+			"languages":   question.Languages,
+		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	result, err := questionCollection.UpdateOne(context.Background(), bson.M{"_id": questionID}, update)
+	filter := bson.M{"_id": questionID, "deletedAt": bson.M{"$exists": false}}
+	if expectedVersion != 0 {
+		filter["version"] = expectedVersion
+	}
+
+	var updated models.Question
+	err = questionCollection.FindOneAndUpdate(context.Background(), filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
 	if err != nil {
-			return nil, err
+		if err == mongo.ErrNoDocuments {
+			if expectedVersion != 0 {
+				if _, err := GetQuestionByID(id); err == nil {
+					return nil, ErrVersionConflict
+				}
+			}
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
 	}
 
-	return result, nil
+	if err := saveQuestionVersion(updated, editorID); err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
-// DeleteQuestion deletes a question by its ID. It returns the result of the deletion and any errors encountered.
-func DeleteQuestion(id string) (*mongo.DeleteResult, error) {
+// DeleteQuestion moves a question to the trash, recording when and by whom it was deleted.
+// Deleted questions are hidden until they are restored or purged. It returns ErrQuestionNotFound when there is no such question.
+func DeleteQuestion(id string, actorID primitive.ObjectID) error {
 	questionID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-			return nil, err
+		return ErrQuestionNotFound
 	}
 
-	result, err := questionCollection.DeleteOne(context.Background(), bson.M{"_id": questionID})
+	result, err := questionCollection.UpdateOne(context.Background(),
+		bson.M{"_id": questionID, "deletedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"deletedAt": time.Now(), "deletedBy": actorID}})
 	if err != nil {
-			return nil, err
+		return err
 	}
-
-	return result, nil
+	if result.MatchedCount == 0 {
+		return ErrQuestionNotFound
+	}
+	return nil
 }
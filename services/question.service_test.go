@@ -0,0 +1,53 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateExamples(t *testing.T) {
+	file := primitive.NewObjectID()
+	tests := []models.Test{
+		{Input: "[1,2],3", ExpectedOutput: "[0,1]"},
+		{InputFile: &file, ExpectedOutput: "[0,1]"},
+	}
+	cases := []struct {
+		name     string
+		examples []models.Example
+		valid    bool
+	}{
+		{"none", nil, true},
+		{"unlinked", []models.Example{{Input: "[3,4],7", Output: "[0,1]", Explanation: "3 + 4 = 7"}}, true},
+		{"linked", []models.Example{{Input: "[1,2],3", Output: "[0,1]", TestIndex: intPointer(0)}}, true},
+		{"linked with spaces", []models.Example{{Input: " [1,2],3\n", Output: "[0,1]\n", TestIndex: intPointer(0)}}, true},
+		{"missing input", []models.Example{{Output: "[0,1]"}}, false},
+		{"missing output", []models.Example{{Input: "[1,2],3"}}, false},
+		{"different input", []models.Example{{Input: "[1,2],4", Output: "[0,1]", TestIndex: intPointer(0)}}, false},
+		{"different output", []models.Example{{Input: "[1,2],3", Output: "[1,0]", TestIndex: intPointer(0)}}, false},
+		{"negative index", []models.Example{{Input: "[1,2],3", Output: "[0,1]", TestIndex: intPointer(-1)}}, false},
+		{"missing test", []models.Example{{Input: "[1,2],3", Output: "[0,1]", TestIndex: intPointer(2)}}, false},
+		{"test in GridFS", []models.Example{{Input: "[1,2],3", Output: "[0,1]", TestIndex: intPointer(1)}}, false},
+		{"second invalid", []models.Example{
+			{Input: "[1,2],3", Output: "[0,1]", TestIndex: intPointer(0)},
+			{Input: "[1,2],3", Output: ""},
+		}, false},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateExamples(models.Question{Tests: tests, Examples: test.examples})
+			if test.valid && err != nil {
+				t.Errorf("validateExamples() error = %v", err)
+			}
+			if !test.valid && !errors.Is(err, ErrInvalidExample) {
+				t.Errorf("validateExamples() error = %v, want ErrInvalidExample", err)
+			}
+		})
+	}
+}
+
+func intPointer(n int) *int {
+	return &n
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// concurrencyRetryAfter is the delay suggested to clients rejected because too many submissions are already running.
+const concurrencyRetryAfter = 5 * time.Second
+
+// limiterIdleTimeout is how long a client's rate limiter is kept after its last submission.
+const limiterIdleTimeout = 10 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// runQuota enforces the submission rate per client and the number of submissions running per client and cluster-wide.
+type runQuota struct {
+	mu               sync.Mutex
+	perMinute        int
+	maxPerClient     int
+	maxTotal         int
+	limiters         map[string]*clientLimiter
+	runningPerClient map[string]int
+	runningTotal     int
+}
+
+var quota *runQuota
+
+// initQuotas loads the runTests quotas from the environment. A value of 0 disables the corresponding limit.
+func initQuotas() {
+	quota = &runQuota{
+		perMinute:        envInt("RUN_TESTS_PER_MINUTE", 10),
+		maxPerClient:     envInt("RUN_TESTS_MAX_CONCURRENT_PER_USER", 2),
+		maxTotal:         envInt("RUN_TESTS_MAX_CONCURRENT", 20),
+		limiters:         map[string]*clientLimiter{},
+		runningPerClient: map[string]int{},
+	}
+	go quota.evictIdle()
+}
+
+// AcquireRunTests reserves a submission slot for a client (a user ID or an IP address).
+// On success it returns a function that must be called once the submission has finished.
+// Otherwise it returns ErrRateLimited or ErrTooManyRunning with the delay after which the client may retry.
+func AcquireRunTests(client string) (func(), time.Duration, error) {
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+
+	if quota.maxPerClient > 0 && quota.runningPerClient[client] >= quota.maxPerClient ||
+		quota.maxTotal > 0 && quota.runningTotal >= quota.maxTotal {
+		return nil, concurrencyRetryAfter, ErrTooManyRunning
+	}
+
+	if quota.perMinute > 0 {
+		entry, ok := quota.limiters[client]
+		if !ok {
+			entry = &clientLimiter{limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(quota.perMinute)), quota.perMinute)}
+			quota.limiters[client] = entry
+		}
+		entry.lastSeen = time.Now()
+
+		reservation := entry.limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			return nil, delay, ErrRateLimited
+		}
+	}
+
+	quota.runningPerClient[client]++
+	quota.runningTotal++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			quota.mu.Lock()
+			defer quota.mu.Unlock()
+			quota.runningTotal--
+			if quota.runningPerClient[client]--; quota.runningPerClient[client] <= 0 {
+				delete(quota.runningPerClient, client)
+			}
+		})
+	}
+	return release, 0, nil
+}
+
+// evictIdle periodically drops the rate limiters of clients that have not submitted recently.
+func (q *runQuota) evictIdle() {
+	for range time.Tick(time.Minute) {
+		q.mu.Lock()
+		for client, entry := range q.limiters {
+			if time.Since(entry.lastSeen) > limiterIdleTimeout {
+				delete(q.limiters, client)
+			}
+		}
+		q.mu.Unlock()
+	}
+}
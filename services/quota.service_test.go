@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireRunTests(t *testing.T) {
+	tests := []struct {
+		name         string
+		perMinute    int
+		maxPerClient int
+		maxTotal     int
+		// clients lists the clients acquiring a slot in turn, without releasing them.
+		clients []string
+		errs    []error
+	}{
+		{"no limits", 0, 0, 0, []string{"a", "a", "a"}, []error{nil, nil, nil}},
+		{"per client", 0, 2, 0, []string{"a", "a", "a", "b"}, []error{nil, nil, ErrTooManyRunning, nil}},
+		{"total", 0, 0, 2, []string{"a", "b", "c"}, []error{nil, nil, ErrTooManyRunning}},
+		{"rate", 2, 0, 0, []string{"a", "a", "a", "b"}, []error{nil, nil, ErrRateLimited, nil}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quota = &runQuota{
+				perMinute:        test.perMinute,
+				maxPerClient:     test.maxPerClient,
+				maxTotal:         test.maxTotal,
+				limiters:         map[string]*clientLimiter{},
+				runningPerClient: map[string]int{},
+			}
+			for i, client := range test.clients {
+				release, retryAfter, err := AcquireRunTests(client)
+				if err != test.errs[i] {
+					t.Fatalf("AcquireRunTests(%q) #%d error = %v, want %v", client, i, err, test.errs[i])
+				}
+				if err != nil && retryAfter <= 0 {
+					t.Errorf("AcquireRunTests(%q) #%d retry after %v, want a positive delay", client, i, retryAfter)
+				}
+				if err == nil && release == nil {
+					t.Errorf("AcquireRunTests(%q) #%d returned no release function", client, i)
+				}
+			}
+		})
+	}
+}
+
+func TestAcquireRunTestsRelease(t *testing.T) {
+	quota = &runQuota{maxPerClient: 1, maxTotal: 1, limiters: map[string]*clientLimiter{}, runningPerClient: map[string]int{}}
+	release, _, err := AcquireRunTests("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AcquireRunTests("b"); err != ErrTooManyRunning {
+		t.Fatalf("AcquireRunTests() while running error = %v, want %v", err, ErrTooManyRunning)
+	}
+
+	// Releasing twice must not free the slot of another submission.
+	release()
+	release()
+	if quota.runningTotal != 0 || len(quota.runningPerClient) != 0 {
+		t.Errorf("after release running = %d, %v, want none", quota.runningTotal, quota.runningPerClient)
+	}
+	if _, retryAfter, err := AcquireRunTests("b"); err != nil {
+		t.Errorf("AcquireRunTests() after release error = %v, retry after %v", err, retryAfter)
+	}
+	if quota.runningTotal != 1 {
+		t.Errorf("running = %d, want 1", quota.runningTotal)
+	}
+}
+
+func TestAcquireRunTestsRetryAfter(t *testing.T) {
+	quota = &runQuota{perMinute: 60, limiters: map[string]*clientLimiter{}, runningPerClient: map[string]int{}}
+	for i := 0; i < 60; i++ {
+		if _, _, err := AcquireRunTests("a"); err != nil {
+			t.Fatalf("AcquireRunTests() #%d error = %v", i, err)
+		}
+	}
+	_, retryAfter, err := AcquireRunTests("a")
+	if err != ErrRateLimited || retryAfter > time.Second {
+		t.Fatalf("AcquireRunTests() past the burst = %v, %v, want %v within a second", retryAfter, err, ErrRateLimited)
+	}
+}
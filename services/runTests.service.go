@@ -2,20 +2,14 @@ package service
 
 import (
 	"LeetCode-server/models"
-	"context"
+	"errors"
 	"fmt"
-	"log"
+	"github.com/google/uuid"
 	"os"
-	"os/exec"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
-	"github.com/google/uuid"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // createTempFile creates a temporary file with the given content, prefix, and extension.
@@ -47,7 +41,7 @@ func findErrorPython(output string) string {
 			errorRe := regexp.MustCompile(`(\w+Error:.*)`)
 			match := errorRe.FindString(line)
 			if match != "" && !strings.HasPrefix(match, "AssertionError:") {
-				return fmt.Sprint("error - ",match)
+				return fmt.Sprint("error - ", match)
 			}
 		}
 	}
@@ -64,13 +58,13 @@ func findErrorJava(output string) string {
 	runtimeErrorMatch := runtimeErrorRegex.FindStringSubmatch(output)
 
 	if len(compilationErrorMatch) > 1 {
-			line := compilationErrorMatch[1]
-			column := compilationErrorMatch[2]
-			errorMessage := compilationErrorMatch[3]
-			return fmt.Sprintf("compilation error - [%s,%s] %s", line, column, errorMessage)
-  } else if len(runtimeErrorMatch) > 0 {
-			return fmt.Sprintf("run time error - %s", runtimeErrorMatch[1])
-  }
+		line := compilationErrorMatch[1]
+		column := compilationErrorMatch[2]
+		errorMessage := compilationErrorMatch[3]
+		return fmt.Sprintf("compilation error - [%s,%s] %s", line, column, errorMessage)
+	} else if len(runtimeErrorMatch) > 0 {
+		return fmt.Sprintf("run time error - %s", runtimeErrorMatch[1])
+	}
 	return ""
 }
 
@@ -90,7 +84,7 @@ func extractFuncNamePython(funcCode string) (string, error) {
 func extractFuncNameJava(funcCode string, returnType string) (string, error) {
 	re := regexp.MustCompile(fmt.Sprintf(`%s\s+(\w+)\s*\(`, regexp.QuoteMeta(returnType)))
 	matches := re.FindStringSubmatch(funcCode)
-	if len(matches) < 1{
+	if len(matches) < 1 {
 		return "", fmt.Errorf("Could not find function name after return type '%s' in the code", returnType)
 	}
 
@@ -141,7 +135,6 @@ func capitalizeBooleans(input string) string {
 	return strings.Join(parts, ", ")
 }
 
-
 // convertInputOutputArray converts the input and output array or matrix string representations into Java array syntax.
 // It returns the converted string or an error if the conversion fails.
 func convertInputOutputArray(input string) (string, error) {
@@ -204,16 +197,17 @@ func convertInputOutputArray(input string) (string, error) {
 
 // runTestJava runs a Java test based on the provided function code, input, and expected output.
 // It prepares the environment, creates necessary files, and runs the test in a Kubernetes pod.
-func runTestJava(funcCode, input, expectedOutput string) (string, error) {
-	dirName := "src" +  uuid.New().String()
-	err := os.MkdirAll(dirName + "/main/java", 0755)
+// The metrics are printed after marker, and memory is the peak heap usage while the function runs.
+func runTestJava(funcCode, input, expectedOutput, marker string) (*execResult, error) {
+	dirName := "src" + uuid.New().String()
+	err := os.MkdirAll(dirName+"/main/java", 0755)
 	if err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	err = os.MkdirAll(dirName + "/test/java", 0755)
+	err = os.MkdirAll(dirName+"/test/java", 0755)
 	if err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	defer func() {
@@ -223,28 +217,28 @@ func runTestJava(funcCode, input, expectedOutput string) (string, error) {
 		}
 	}()
 
-	_, err = createTempFile(funcCode, dirName + "/main/java/Main", "java")
+	_, err = createTempFile(funcCode, dirName+"/main/java/Main", "java")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	convertedInput, err := convertInputOutputArray(input)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	convertedOutput, err := convertInputOutputArray(expectedOutput)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	modifier, err := extractReturnType(funcCode)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	funcName, err := extractFuncNameJava(funcCode, modifier)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var assert string
@@ -258,7 +252,10 @@ func runTestJava(funcCode, input, expectedOutput string) (string, error) {
 	}
 
 	testCode := fmt.Sprintf(
-`import java.util.Arrays;
+		`import java.lang.management.ManagementFactory;
+import java.lang.management.MemoryPoolMXBean;
+import java.lang.management.MemoryType;
+import java.util.Arrays;
 import org.junit.jupiter.api.Test;
 import static org.junit.jupiter.api.Assertions.assertEquals;
 import static org.junit.jupiter.api.Assertions.assertArrayEquals;
@@ -270,7 +267,19 @@ public class MainTest {
 	@Test
 	public void testFunc() {
 			try {
+					for (MemoryPoolMXBean pool : ManagementFactory.getMemoryPoolMXBeans()) {
+							pool.resetPeakUsage();
+					}
+					long start = System.nanoTime();
 					%s result = main.%s(%s);
+					double elapsed = (System.nanoTime() - start) / 1e6;
+					long peak = 0;
+					for (MemoryPoolMXBean pool : ManagementFactory.getMemoryPoolMXBeans()) {
+							if (pool.getType() == MemoryType.HEAP) {
+									peak += pool.getPeakUsage().getUsed();
+							}
+					}
+					System.out.println("%s runtime_ms=" + elapsed + " memory_kb=" + peak / 1024);
 					%s(%s, result);
 			} catch (AssertionError e) {
 					System.out.print("Expected but got ");
@@ -278,95 +287,35 @@ public class MainTest {
 					throw e; 
 			}
 	}
-}`, modifier, funcName, convertedInput, assert, convertedOutput, print)
-
-	_, err = createTempFile(testCode, dirName + "/test/java/MainTest", "java")
-	if err != nil {
-		return "", err
-	}
-
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		return "", fmt.Errorf("error","cannot connect to k8s KUBECONFIG is not exist")
-	}
-
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		log.Fatalf("Failed to build kubeconfig: %v", err)
-	}
+}`, modifier, funcName, convertedInput, marker, assert, convertedOutput, print)
 
-	clientset, err := kubernetes.NewForConfig(config)
+	_, err = createTempFile(testCode, dirName+"/test/java/MainTest", "java")
 	if err != nil {
-		log.Fatalf("Failed to create clientset: %v", err)
-	}
-
-	podName := "java-test-pod" + uuid.New().String()
-
-	pod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: podName,
-		},
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					Name:  "java-test",
-					Image: "miryamw/java-test:latest",
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							"memory": resource.MustParse("512Mi"),
-							"cpu":    resource.MustParse("500m"),
-						},
-						Limits: corev1.ResourceList{
-							"memory": resource.MustParse("1Gi"),
-							"cpu":    resource.MustParse("1"),
-						},
-					},
-				},
-			},
-			RestartPolicy: corev1.RestartPolicyNever,
-		},
-	}
-
-	_, err = clientset.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
-	if err != nil {
-		log.Fatalf("Failed to create pod: %v", err)
+		return nil, err
 	}
 
-	for {
-		podStatus, err := clientset.CoreV1().Pods("default").Get(context.TODO(), podName, metav1.GetOptions{})
-		if err != nil {
-			log.Fatalf("Failed to get pod status: %v", err)
-		}
-		if podStatus.Status.Phase == corev1.PodRunning {
-			break
-		}
-		time.Sleep(5 * time.Second)
-	}
-
-	cmd := exec.Command("kubectl", "cp", dirName, podName+":/app/src/")
-	err = cmd.Run()
+	podName, err := sandboxPools["java"].checkout()
 	if err != nil {
-		log.Fatalf("Failed to copy files: %v", err)
+		return nil, err
 	}
+	defer sandboxPools["java"].release(podName)
 
-	cmd = exec.Command("kubectl", "exec", "-it", podName, "--", "mvn", "test")
-	output, _ := cmd.CombinedOutput()
-
-	err = clientset.CoreV1().Pods("default").Delete(context.TODO(), podName, metav1.DeleteOptions{})
+	err = copyToPod(dirName, podName, "/app/src")
 	if err != nil {
-		log.Fatalf("Failed to delete pod: %v", err)
+		return nil, err
 	}
 
-	return string(output), nil
+	return execInPod(podName, sandboxCommand("mvn -o test"), nil)
 }
 
 // runTestPython runs a Python test based on the provided function code, input, and expected output.
 // It prepares the environment, creates necessary files, and runs the test in a Kubernetes pod
-func runTestPython(funcCode, input, expectedOutput string) (string, error) {
+// The metrics are printed after marker, and memory is the peak resident memory of the test process.
+func runTestPython(funcCode, input, expectedOutput, marker string) (*execResult, error) {
 	dirName := "my_tests" + uuid.New().String()
 	err := os.MkdirAll(dirName, 0755)
 	if err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	defer func() {
@@ -378,112 +327,79 @@ func runTestPython(funcCode, input, expectedOutput string) (string, error) {
 
 	funcName, err := extractFuncNamePython(funcCode)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	_, err = createTempFile(funcCode, dirName + "/func", "py")
+	_, err = createTempFile(funcCode, dirName+"/func", "py")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	formatedInput:= capitalizeBooleans(input)
-	formatedOutput:= capitalizeBooleans(expectedOutput)
+	formatedInput := capitalizeBooleans(input)
+	formatedOutput := capitalizeBooleans(expectedOutput)
 
 	testCode := fmt.Sprintf(`
+import resource
+import time
 from func import *
 
 def test():
+	start = time.perf_counter()
 	result = %s(%s)
+	elapsed = (time.perf_counter() - start) * 1000
+	print(f"%s runtime_ms={elapsed} memory_kb={resource.getrusage(resource.RUSAGE_SELF).ru_maxrss}")
 	assert result == %s, f"Expected but got {result}"
-`, funcName, formatedInput, formatedOutput)
+`, funcName, formatedInput, marker, formatedOutput)
+
+	_, err = createTempFile(testCode, dirName+"/test_func", "py")
+	if err != nil {
+		return nil, err
+	}
+
+	podName, err := sandboxPools["python"].checkout()
+	if err != nil {
+		return nil, err
+	}
+	defer sandboxPools["python"].release(podName)
 
-	_, err = createTempFile(testCode, dirName + "/test_func", "py")
+	err = copyToPod(dirName, podName, "/app/my_tests")
 	if err != nil {
-		return "", err
-	}
-
-	kubeconfig := os.Getenv("KUBECONFIG")
-  if kubeconfig == "" {
-		return "", fmt.Errorf("error","cannot connect to k8s KUBECONFIG is not exist")
-  }
-
-  config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-  if err != nil {
-	  log.Fatalf("Failed to build kubeconfig: %v", err)
-  }
-
-  clientset, err := kubernetes.NewForConfig(config)
-  if err != nil {
-	  log.Fatalf("Failed to create clientset: %v", err)
-  }
-  podName := "python-test-pod" + uuid.New().String()
-
-  pod := &corev1.Pod{
-	  ObjectMeta: metav1.ObjectMeta{
-		  Name: podName,
-	  },
-	  Spec: corev1.PodSpec{
-		  Containers: []corev1.Container{
-			  {
-				  Name:  "python-test",
-				  Image: "miryamw/python-test:latest",
-				  Resources: corev1.ResourceRequirements{
-					  Requests: corev1.ResourceList{
-						  "memory": resource.MustParse("512Mi"),
-						  "cpu":    resource.MustParse("500m"), 
-					  },
-					  Limits: corev1.ResourceList{
-						  "memory": resource.MustParse("1Gi"), 
-						  "cpu":    resource.MustParse("1"),   
-					  },
-				  },
-			  },
-		  },
-		  RestartPolicy: corev1.RestartPolicyNever, 
-	  },
-  }
-
-  _, err = clientset.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
-  if err != nil {
-	  log.Fatalf("Failed to create pod: %v", err)
-  }
-
-  for {
-	  podStatus, err := clientset.CoreV1().Pods("default").Get(context.TODO(), podName, metav1.GetOptions{})
-	  if err != nil {
-		  log.Fatalf("Failed to get pod status: %v", err)
-	  }
-	  if podStatus.Status.Phase == corev1.PodRunning {
-		  break
-	  }
-	  time.Sleep(5 * time.Second) 
-  }
-
-  cmd := exec.Command("kubectl", "cp", dirName, podName+":/app/my_tests/")
-  err = cmd.Run()
-  if err != nil {
-	  log.Fatalf("Failed to copy files: %v", err)
-  }
-
-  cmd = exec.Command("kubectl", "exec", "-it", podName, "--", "pytest", "/app/my_tests")
-  output, _ := cmd.CombinedOutput()
-
-  err = clientset.CoreV1().Pods("default").Delete(context.TODO(), podName, metav1.DeleteOptions{})
-  if err != nil {
-	  log.Fatalf("Failed to delete pod: %v", err)
-  }
-
-  return string(output), nil
+		return nil, err
+	}
+
+	return execInPod(podName, sandboxCommand("pytest -s -p no:cacheprovider /app/my_tests"), nil)
 }
 
-type runTest func(string, string, string) (string, error)
-type findError func(output string) string 
+// metricsMarker returns a random marker for the metrics line of one test run, so that the submitted code, which can't
+// know it in advance, can't print a metrics line of its own.
+func metricsMarker() string {
+	return "__METRICS_" + strings.ReplaceAll(uuid.New().String(), "-", "") + "__"
+}
+
+// parseMetrics extracts the runtime (in milliseconds) and peak memory (in kilobytes) printed after marker by the generated
+// test code. The last line is used since the generated code prints it after the submitted function has run.
+// It returns zero values when the test did not reach the measurement point.
+func parseMetrics(output, marker string) (float64, int64) {
+	re := regexp.MustCompile(regexp.QuoteMeta(marker) + ` runtime_ms=([0-9.eE+-]+) memory_kb=(\d+)`)
+	matches := re.FindAllStringSubmatch(output, -1)
+	if matches == nil {
+		return 0, 0
+	}
+	match := matches[len(matches)-1]
+	runtimeMs, _ := strconv.ParseFloat(match[1], 64)
+	memoryKb, _ := strconv.ParseInt(match[2], 10, 64)
+	return runtimeMs, memoryKb
+}
+
+type runTest func(string, string, string, string) (*execResult, error)
+type findError func(output string) string
 
 // The RunTests function executes a series of tests for a given function code in a specified programming language,
-// comparing the actual output with the expected output. 
-//It returns the results, including success/failure status, error messages, and any discrepancies found during the tests.
-func RunTests(funcCode string, questionId string, language string) ([]models.TestResult, error) {
+// comparing the actual output with the expected output.
+// It returns the results, including success/failure status, error messages, and any discrepancies found during the tests,
+// along with the version of the question the tests were taken from.
+func RunTests(funcCode string, questionId string, language string) ([]models.TestResult, int, error) {
 	runTestMap := map[string]runTest{
-		"java":     runTestJava,
+		"java":   runTestJava,
 		"python": runTestPython,
 	}
 
@@ -491,10 +407,17 @@ func RunTests(funcCode string, questionId string, language string) ([]models.Tes
 		"java":   findErrorJava,
 		"python": findErrorPython,
 	}
-	
+
+	if _, ok := runTestMap[language]; !ok {
+		return nil, 0, ErrUnsupportedLanguage
+	}
+
 	question, err := GetQuestionByID(questionId)
 	if err != nil {
-			return nil, fmt.Errorf("error fetching question: %v", err)
+		return nil, 0, fmt.Errorf("error fetching question: %v", err)
+	}
+	if len(question.Languages) > 0 && !slices.Contains(question.Languages, language) {
+		return nil, 0, fmt.Errorf("Question does not support %s", language)
 	}
 
 	var results []models.TestResult
@@ -503,68 +426,80 @@ func RunTests(funcCode string, questionId string, language string) ([]models.Tes
 
 	//runAllTests
 	for i, test := range question.Tests {
-			out, err := runTestMap[language] (funcCode, test.Input, test.ExpectedOutput)
-			var errors []models.ErrorLine
-			var comments string
-			passed := true
-			output := ""
-			if err != nil {
+		test, err := loadTest(test)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error fetching test data: %v", err)
+		}
+		marker := metricsMarker()
+		result, err := runTestMap[language](funcCode, test.Input, test.ExpectedOutput, marker)
+		input, expectedOutput := previewTestData(test.Input), previewTestData(test.ExpectedOutput)
+		internalError := errors.Is(err, errSandbox)
+		var errors []models.ErrorLine
+		var comments string
+		var out string
+		passed := true
+		output := ""
+		if err != nil {
+			passed = false
+			comments = err.Error()
+		} else {
+			out = result.Stdout + result.Stderr
+			passed = result.ExitCode == 0
+			//find compilation / run time errors
+			errorMessage := findErrorMap[language](out)
+			if errorMessage != "" {
 				passed = false
-				comments = err.Error()
-			} else {
-				//find compilation / run time errors
-				errorMessage := findErrorMap[language](out)
-				if errorMessage != "" {
+				comments = errorMessage
+			}
+		}
+		if comments == "" {
+			//find another failures
+			for _, keyword := range failedKeywords {
+				if strings.Contains(strings.ToLower(out), keyword) {
 					passed = false
-					comments = errorMessage
+					break
 				}
 			}
-			if comments == "" {
-				//find another failures
-				for _, keyword := range failedKeywords {
-					if strings.Contains(strings.ToLower(out), keyword) {
-						passed = false
-						break
-				  }
-				}
-				var match []string
-				//find the wronע output
-				allMatches := failureRegex.FindAllStringSubmatch(out, -1)
-				if len(allMatches) >= 2 {
-					match = allMatches[1]
-				} else if len(allMatches) == 1 {
-					match = allMatches[0]
+			var match []string
+			//find the wronע output
+			allMatches := failureRegex.FindAllStringSubmatch(out, -1)
+			if len(allMatches) >= 2 {
+				match = allMatches[1]
+			} else if len(allMatches) == 1 {
+				match = allMatches[0]
+			}
+			if match != nil {
+				parts := strings.SplitN(match[0], " ", 2)
+				if len(parts) > 1 {
+					output = parts[1]
 				}
-				if match != nil {
-					parts := strings.SplitN(match[0], " ", 2)
-					if len(parts) > 1 {
-						output = parts[1]
-					}
-					comments = fmt.Sprintf("Test failed for input %s: output indicates failure: %s", test.Input, match[0])
-				} else if passed == false{
-					comments = fmt.Sprintf("Test failed for input %s", test.Input)
-			  }
-		  }
-
-			//put the correct output
-	    if output == "" && passed {
-			  output = test.ExpectedOutput
-	    }
-
-			//append to results array
-	    results = append(results, models.TestResult{
-			  TestNumber:     i + 1,
-			  Passed:         passed,
-			  Comments:       comments,
-			  Input:          test.Input,
-			  ExpectedOutput: test.ExpectedOutput,
-			  Output:         output,
-			  Errors:         errors,
-		  })
-	}
-
-	return results, nil
-}
+				comments = fmt.Sprintf("Test failed for input %s: output indicates failure: %s", input, match[0])
+			} else if passed == false {
+				comments = fmt.Sprintf("Test failed for input %s", input)
+			}
+		}
 
+		runtimeMs, memoryKb := parseMetrics(out, marker)
 
+		//put the correct output
+		if output == "" && passed {
+			output = expectedOutput
+		}
 
+		//append to results array
+		results = append(results, models.TestResult{
+			TestNumber:     i + 1,
+			Passed:         passed,
+			Comments:       comments,
+			Input:          input,
+			ExpectedOutput: expectedOutput,
+			Output:         output,
+			Errors:         errors,
+			RuntimeMs:      runtimeMs,
+			MemoryKb:       memoryKb,
+			InternalError:  internalError,
+		})
+	}
+
+	return results, question.Version, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMetrics(t *testing.T) {
+	marker := metricsMarker()
+	tests := []struct {
+		name    string
+		output  string
+		runtime float64
+		memory  int64
+	}{
+		{"metrics", marker + " runtime_ms=1.5 memory_kb=2048\n1 passed", 1.5, 2048},
+		{"exponent", marker + " runtime_ms=2.5e-05 memory_kb=10", 2.5e-05, 10},
+		{"no metrics", "1 failed", 0, 0},
+		{"other marker", "__METRICS__ runtime_ms=0.001 memory_kb=1", 0, 0},
+		{"last line", marker + " runtime_ms=0.001 memory_kb=1\n" + marker + " runtime_ms=12 memory_kb=4096", 12, 4096},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runtime, memory := parseMetrics(test.output, marker)
+			if runtime != test.runtime || memory != test.memory {
+				t.Errorf("parseMetrics() = %v, %v, want %v, %v", runtime, memory, test.runtime, test.memory)
+			}
+		})
+	}
+
+	if other := metricsMarker(); other == marker || strings.Contains(other, "-") {
+		t.Errorf("metricsMarker() = %q after %q, want a different marker without dashes", other, marker)
+	}
+}
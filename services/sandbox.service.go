@@ -0,0 +1,362 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	watchtools "k8s.io/client-go/tools/watch"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+const (
+	sandboxLabel         = "leetcode-sandbox"
+	sandboxNetworkPolicy = "leetcode-sandbox-deny-all"
+	// sandboxUser is the unprivileged user created in the tests-images.
+	sandboxUser = 1000
+	// sandboxMaxProcesses bounds the processes a submission may start, to stop fork bombs.
+	sandboxMaxProcesses = 256
+	// sandboxExecTimeout bounds the time a command may run in a sandbox pod, including the compilation of Java tests.
+	sandboxExecTimeout = 30 * time.Second
+)
+
+// errSandbox marks failures of the sandbox infrastructure, as opposed to failures of the submitted code.
+var errSandbox = errors.New("internal error")
+
+// errTimeLimit reports a command that did not finish within sandboxExecTimeout.
+var errTimeLimit = errors.New("time limit exceeded")
+
+// podStartFailures are the container waiting reasons after which a pod won't start without intervention.
+var podStartFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"CrashLoopBackOff":           true,
+}
+
+var (
+	kubeMutex     sync.Mutex
+	kubeConfig    *rest.Config
+	kubeClientset *kubernetes.Clientset
+)
+
+// execResult holds the outcome of a command executed in a pod.
+type execResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// kubeClient returns the Kubernetes client built from KUBECONFIG, creating it and the sandbox network policy on first use.
+// Failures are not remembered, so that the next call tries again.
+func kubeClient() (*kubernetes.Clientset, error) {
+	kubeMutex.Lock()
+	defer kubeMutex.Unlock()
+	if kubeClientset != nil {
+		return kubeClientset, nil
+	}
+
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		return nil, fmt.Errorf("%w - cannot connect to k8s KUBECONFIG is not exist", errSandbox)
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w - failed to build kubeconfig: %w", errSandbox, err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w - failed to create clientset: %w", errSandbox, err)
+	}
+
+	if err := ensureSandboxNetworkPolicy(clientset); err != nil {
+		return nil, err
+	}
+	kubeConfig, kubeClientset = config, clientset
+	return kubeClientset, nil
+}
+
+// ensureSandboxNetworkPolicy creates a NetworkPolicy that denies all ingress and egress traffic of the sandbox pods.
+func ensureSandboxNetworkPolicy(clientset *kubernetes.Clientset) error {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: sandboxNetworkPolicy,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": sandboxLabel}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+
+	_, err := clientset.NetworkingV1().NetworkPolicies(sandbox.Namespace).Create(context.TODO(), policy, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("%w - failed to create sandbox network policy: %w", errSandbox, err)
+	}
+	return nil
+}
+
+// sandboxPod builds the spec of a pod that runs untrusted code with the image and resources of a language and the
+// configured scheduling settings. It runs as a non-root user without capabilities, service account token or network
+// access, and its root filesystem is read-only except for tmpfs mounts at writableDirs.
+func sandboxPod(name, containerName string, language languageConfig, writableDirs ...string) *corev1.Pod {
+	falseValue := false
+	trueValue := true
+	user := int64(sandboxUser)
+	workDirLimit := resource.MustParse("64Mi")
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for i, dir := range writableDirs {
+		volumeName := fmt.Sprintf("work-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &workDirLimit},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: volumeName, MountPath: dir})
+	}
+
+	var pullSecrets []corev1.LocalObjectReference
+	for _, secret := range sandbox.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	var runtimeClassName *string
+	if sandbox.RuntimeClassName != "" {
+		runtimeClassName = &sandbox.RuntimeClassName
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"app": sandboxLabel},
+		},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: &falseValue,
+			EnableServiceLinks:           &falseValue,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   &trueValue,
+				RunAsUser:      &user,
+				RunAsGroup:     &user,
+				FSGroup:        &user,
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:      containerName,
+					Image:     language.Image,
+					Resources: language.Resources,
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: &falseValue,
+						Privileged:               &falseValue,
+						ReadOnlyRootFilesystem:   &trueValue,
+						Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+					},
+					VolumeMounts: mounts,
+				},
+			},
+			Volumes:          volumes,
+			RestartPolicy:    corev1.RestartPolicyNever,
+			NodeSelector:     sandbox.NodeSelector,
+			Tolerations:      sandbox.Tolerations,
+			ImagePullSecrets: pullSecrets,
+			RuntimeClassName: runtimeClassName,
+		},
+	}
+}
+
+// sandboxCommand wraps a command so that it runs with the sandbox process limit.
+func sandboxCommand(command string) []string {
+	return []string{"sh", "-c", fmt.Sprintf("ulimit -u %d && exec %s", sandboxMaxProcesses, command)}
+}
+
+// execInPod runs a command in the first container of a pod, optionally feeding it stdin.
+// A non-zero exit code is reported in the result rather than as an error, and a command still running after
+// sandboxExecTimeout fails with errTimeLimit. It keeps running in the pod, which must then be deleted.
+func execInPod(podName string, command []string, stdin io.Reader) (*execResult, error) {
+	request := kubeClientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(sandbox.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Command: command,
+			Stdin:   stdin != nil,
+			Stdout:  true,
+			Stderr:  true,
+		}, scheme.ParameterCodec)
+
+	// Prefer the WebSocket protocol and fall back to SPDY for API servers that don't support it yet.
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(kubeConfig, "GET", request.URL().String())
+	if err != nil {
+		return nil, fmt.Errorf("%w - failed to create websocket executor: %w", errSandbox, err)
+	}
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(kubeConfig, "POST", request.URL())
+	if err != nil {
+		return nil, fmt.Errorf("%w - failed to create spdy executor: %w", errSandbox, err)
+	}
+	executor, err := remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w - failed to create executor: %w", errSandbox, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxExecTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	result := &execResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errTimeLimit
+		}
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%w - failed to execute command in pod: %w", errSandbox, err)
+		}
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result, nil
+}
+
+// copyToPod copies the contents of a local directory into a directory of a pod by streaming a tar archive to tar in the container.
+func copyToPod(localDir, podName, podDir string) error {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	err := filepath.WalkDir(localDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == localDir {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name, err = filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(header.Name)
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w - failed to archive files: %w", errSandbox, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("%w - failed to archive files: %w", errSandbox, err)
+	}
+
+	result, err := execInPod(podName, []string{"tar", "-xf", "-", "-C", podDir}, &archive)
+	if err == errTimeLimit {
+		return fmt.Errorf("%w - copying files timed out", errSandbox)
+	}
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%w - failed to copy files: %s", errSandbox, result.Stderr)
+	}
+	return nil
+}
+
+// deletePod removes a sandbox pod, logging instead of failing since the test result is already known.
+func deletePod(podName string) {
+	err := kubeClientset.CoreV1().Pods(sandbox.Namespace).Delete(context.TODO(), podName, metav1.DeleteOptions{})
+	if err != nil {
+		log.Printf("failed to delete pod %s: %v", podName, err)
+	}
+}
+
+// waitForPodRunning watches a pod until it reaches the Running phase. It fails as soon as the pod can't start,
+// for example because its image can't be pulled, and after the configured podStartTimeout when the pod can't be scheduled.
+func waitForPodRunning(podName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sandbox.PodStartTimeout.Duration)
+	defer cancel()
+
+	lw := cache.NewListWatchFromClient(kubeClientset.CoreV1().RESTClient(), "pods", sandbox.Namespace,
+		fields.OneTermEqualSelector("metadata.name", podName))
+
+	pending := "pod was not scheduled"
+	_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		if event.Type == watch.Deleted {
+			return false, fmt.Errorf("%w - pod %s was deleted before it started", errSandbox, podName)
+		}
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			return false, nil
+		}
+
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, fmt.Errorf("%w - pod %s stopped before it started: %s %s", errSandbox, podName, pod.Status.Reason, pod.Status.Message)
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				pending = fmt.Sprintf("pod could not be scheduled: %s", condition.Message)
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil {
+				if podStartFailures[waiting.Reason] {
+					return false, fmt.Errorf("%w - pod %s could not start: %s: %s", errSandbox, podName, waiting.Reason, waiting.Message)
+				}
+				pending = fmt.Sprintf("container is waiting: %s", waiting.Reason)
+			}
+		}
+		return false, nil
+	})
+
+	if err == watchtools.ErrWatchClosed || ctx.Err() != nil {
+		return fmt.Errorf("%w - pod %s did not start within %s, %s", errSandbox, podName, sandbox.PodStartTimeout.Duration, pending)
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"html"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// snippetRadius is the number of characters kept on each side of the first match in a description snippet.
+const snippetRadius = 80
+
+// initSearch creates the text index over question titles and descriptions, ranking title matches higher.
+func initSearch() error {
+	_, err := questionCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+		Options: options.Index().SetName("question_text").SetWeights(bson.M{"title": 10, "description": 1}),
+	})
+	return err
+}
+
+// SearchQuestions runs a full-text search over question titles and descriptions, optionally filtered by level and tag.
+// Results are ordered by relevance and include a description snippet with the matched words wrapped in <mark> tags.
+func SearchQuestions(text string, filter models.QuestionFilter) (*models.QuestionSearchPage, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptySearch
+	}
+
+	query := bson.M{"$text": bson.M{"$search": text}, "deletedAt": bson.M{"$exists": false}}
+	if filter.Level != 0 {
+		query["level"] = filter.Level
+	}
+	if filter.Tag != "" {
+		query["tags"] = NormalizeTag(filter.Tag)
+	}
+
+	total, err := questionCollection.CountDocuments(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((filter.Page - 1) * filter.PageSize)).
+		SetLimit(int64(filter.PageSize)).
+		SetProjection(bson.M{"title": 1, "description": 1, "level": 1, "tags": 1, "languages": 1, "authorId": 1, "score": score})
+	cursor, err := questionCollection.Find(context.Background(), query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	page := models.QuestionSearchPage{Results: []models.QuestionSearchResult{}, Total: total, Page: filter.Page, PageSize: filter.PageSize}
+	if err := cursor.All(context.Background(), &page.Results); err != nil {
+		return nil, err
+	}
+
+	terms := searchTermsPattern(text)
+	for i := range page.Results {
+		page.Results[i].Snippet = highlightSnippet(page.Results[i].Description, terms)
+		page.Results[i].Difficulty = models.Difficulties[page.Results[i].Level]
+	}
+	return &page, nil
+}
+
+// searchTermsPattern builds a case-insensitive pattern matching words that start with one of the searched terms,
+// so that "sorted" is highlighted for a search for "sort". Negated terms are not highlighted. The word is the first
+// group of a match, after the character before it: word boundaries are written out since \b only knows ASCII letters.
+func searchTermsPattern(text string) *regexp.Regexp {
+	var terms []string
+	for _, word := range strings.Fields(strings.ReplaceAll(text, `"`, " ")) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		terms = append(terms, regexp.QuoteMeta(word))
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])((?:` + strings.Join(terms, "|") + `)[\p{L}\p{N}_]*)`)
+}
+
+// highlightSnippet cuts the part of a description around the first match and wraps every match in <mark> tags.
+// The rest of the snippet is HTML-escaped.
+func highlightSnippet(description string, terms *regexp.Regexp) string {
+	runes := []rune(description)
+	start, end := 0, len(runes)
+	if terms != nil {
+		if match := terms.FindStringSubmatchIndex(description); match != nil {
+			matchStart := len([]rune(description[:match[2]]))
+			start = max(0, matchStart-snippetRadius)
+		}
+	}
+	end = min(end, start+2*snippetRadius)
+	snippet := string(runes[start:end])
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	last := 0
+	if terms != nil {
+		for _, match := range terms.FindAllStringSubmatchIndex(snippet, -1) {
+			builder.WriteString(html.EscapeString(snippet[last:match[2]]))
+			builder.WriteString("<mark>" + html.EscapeString(snippet[match[2]:match[3]]) + "</mark>")
+			last = match[3]
+		}
+	}
+	builder.WriteString(html.EscapeString(snippet[last:]))
+	if end < len(runes) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
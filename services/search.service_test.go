@@ -0,0 +1,78 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchTermsPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		matches []string
+		others  []string
+	}{
+		{"word prefix", "sort", []string{"sort", "Sorted", "SORTING"}, []string{"resort", "sor"}},
+		{"several terms", "binary tree", []string{"binary", "trees"}, []string{"graph"}},
+		{"phrase", `"linked list"`, []string{"linked", "lists"}, []string{"link"}},
+		{"negated term", "array -matrix", []string{"arrays"}, []string{"matrix"}},
+		{"special characters", "c++ a.b", []string{"c++", "a.b"}, []string{"axb"}},
+		{"non-ASCII letters", "ספר", []string{"ספר", "ספרים"}, []string{"הספר"}},
+		{"accented prefix", "élément", []string{"éléments"}, []string{"aélément"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pattern := searchTermsPattern(test.text)
+			if pattern == nil {
+				t.Fatal("searchTermsPattern() = nil")
+			}
+			for _, word := range test.matches {
+				if match := pattern.FindStringSubmatch(word); match == nil || !strings.EqualFold(match[1], word) {
+					t.Errorf("pattern matches %q in %q, want the whole word", match, word)
+				}
+			}
+			for _, word := range test.others {
+				if pattern.MatchString(word) {
+					t.Errorf("pattern matches %q", word)
+				}
+			}
+		})
+	}
+
+	for _, text := range []string{"", "   ", "-excluded", `""`} {
+		if pattern := searchTermsPattern(text); pattern != nil {
+			t.Errorf("searchTermsPattern(%q) = %v, want nil", text, pattern)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	long := strings.Repeat("a ", 100)
+	tests := []struct {
+		name        string
+		description string
+		search      string
+		want        string
+	}{
+		{"match", "Return the sorted array.", "sort", "Return the <mark>sorted</mark> array."},
+		{"every match", "Sort it, then sort again.", "sort", "<mark>Sort</mark> it, then <mark>sort</mark> again."},
+		{"no match", "Return the array.", "tree", "Return the array."},
+		{"hebrew", "החזירו את המערך הממוין, מערך חדש", "מערך", "החזירו את המערך הממוין, <mark>מערך</mark> חדש"},
+		{"adjacent matches", "sort sort", "sort", "<mark>sort</mark> <mark>sort</mark>"},
+		{"no terms", "Return the array.", "-tree", "Return the array."},
+		{"escapes html", "Use <b> & sort", "sort", "Use &lt;b&gt; &amp; <mark>sort</mark>"},
+		{"cut around match", long + "target " + long, "target",
+			"…" + strings.Repeat("a ", 40) + "<mark>target</mark> " + strings.Repeat("a ", 36) + "a…"},
+		{"cut at start", "target " + long, "target", "<mark>target</mark> " + strings.Repeat("a ", 76) + "a…"},
+		{"multi-byte characters", strings.Repeat("é", 100) + " target", "target",
+			"…" + strings.Repeat("é", 79) + " <mark>target</mark>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := highlightSnippet(test.description, searchTermsPattern(test.search))
+			if got != test.want {
+				t.Errorf("highlightSnippet() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
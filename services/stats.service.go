@@ -0,0 +1,176 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ParseDifficulty returns the level of a difficulty name, in any case.
+func ParseDifficulty(difficulty string) (int, error) {
+	for level, name := range models.Difficulties {
+		if strings.EqualFold(name, difficulty) {
+			return level, nil
+		}
+	}
+	return 0, ErrInvalidDifficulty
+}
+
+// resolveDifficulty sets the level of a question written with a Difficulty name instead of a Level.
+func resolveDifficulty(question *models.Question) error {
+	if question.Difficulty == "" {
+		return nil
+	}
+	level, err := ParseDifficulty(question.Difficulty)
+	if err != nil {
+		return err
+	}
+	if question.Level != 0 && question.Level != level {
+		return ErrInvalidDifficulty
+	}
+	question.Level = level
+	return nil
+}
+
+// legacyLevel maps a level stored before the levels were restricted to the difficulties to the nearest difficulty:
+// levels below Easy become Easy and levels above Hard become Hard.
+func legacyLevel(level int) int {
+	return min(max(level, models.LevelEasy), models.LevelHard)
+}
+
+// migrateLegacyLevels moves the questions with a level outside the difficulties, stored before the levels were restricted,
+// to the nearest difficulty with legacyLevel. Without it these questions would have no difficulty and could not be edited
+// anymore. Each question is updated through UpdateQuestion on behalf of its author, so the change gets a version of its
+// own. Questions that can't be migrated, such as those in the trash, keep their level until the next start.
+func migrateLegacyLevels() error {
+	cursor, err := questionCollection.Find(context.Background(), bson.M{
+		"deletedAt": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"level": bson.M{"$lt": models.LevelEasy}},
+			bson.M{"level": bson.M{"$gt": models.LevelHard}},
+			bson.M{"level": bson.M{"$exists": false}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var questions []models.Question
+	if err := cursor.All(context.Background(), &questions); err != nil {
+		return err
+	}
+
+	for _, question := range questions {
+		level := legacyLevel(question.Level)
+		question.Level = level
+		_, err := UpdateQuestion(question.ID.Hex(), question.AuthorID, question.Version, question)
+		if err != nil {
+			log.Printf("failed to move question %s with a legacy level to %s: %v", question.ID.Hex(), models.Difficulties[level], err)
+			continue
+		}
+		log.Printf("moved question %s with a legacy level to %s", question.ID.Hex(), models.Difficulties[level])
+	}
+	return nil
+}
+
+// questionStatsTTL is how long the statistics of a question are reused before being computed again.
+const questionStatsTTL = time.Minute
+
+// questionStatsCache holds the recently computed statistics of the questions, so that reading a popular question
+// doesn't aggregate its submissions every time.
+var questionStatsCache = struct {
+	sync.Mutex
+	entries map[primitive.ObjectID]cachedQuestionStats
+}{entries: map[primitive.ObjectID]cachedQuestionStats{}}
+
+type cachedQuestionStats struct {
+	stats     *models.QuestionStats
+	expiresAt time.Time
+}
+
+// GetQuestionStats returns the submission statistics of a question, computed at most once per questionStatsTTL.
+// It returns nil, after logging the error, when the statistics can't be computed, so that the question can still be read.
+func GetQuestionStats(question *models.Question) *models.QuestionStats {
+	questionStatsCache.Lock()
+	cached, ok := questionStatsCache.entries[question.ID]
+	questionStatsCache.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.stats
+	}
+
+	stats, err := computeQuestionStats(question.ID)
+	if err != nil {
+		log.Printf("failed to compute the statistics of question %s: %v", question.ID.Hex(), err)
+		return nil
+	}
+
+	questionStatsCache.Lock()
+	defer questionStatsCache.Unlock()
+	now := time.Now()
+	for id, entry := range questionStatsCache.entries {
+		if now.After(entry.expiresAt) {
+			delete(questionStatsCache.entries, id)
+		}
+	}
+	questionStatsCache.entries[question.ID] = cachedQuestionStats{stats: stats, expiresAt: now.Add(questionStatsTTL)}
+	return stats
+}
+
+// computeQuestionStats computes the submission statistics of a question: the number of submissions, how many were accepted,
+// how many users solved the question and how many attempts they needed on average.
+func computeQuestionStats(questionID primitive.ObjectID) (*models.QuestionStats, error) {
+	cursor, err := submissionCollection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"questionId": questionID}}},
+		// The questionId and createdAt index serves the match and the sort, and only the fields used by the statistics are
+		// kept so that the code and results of the submissions are not carried through the pipeline.
+		{{Key: "$project", Value: bson.M{"_id": 0, "userId": 1, "accepted": 1, "createdAt": 1}}},
+		{{Key: "$sort", Value: bson.M{"createdAt": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$userId",
+			"total":    bson.M{"$sum": 1},
+			"accepted": bson.M{"$sum": bson.M{"$cond": bson.A{"$accepted", 1, 0}}},
+			"results":  bson.M{"$push": "$accepted"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"firstAccepted": bson.M{"$indexOfArray": bson.A{"$results", true}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      nil,
+			"total":    bson.M{"$sum": "$total"},
+			"accepted": bson.M{"$sum": "$accepted"},
+			"solvers":  bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$firstAccepted", 0}}, 1, 0}}},
+			"attempts": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$firstAccepted", 0}}, bson.M{"$add": bson.A{"$firstAccepted", 1}}, 0}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Total    int64 `bson:"total"`
+		Accepted int64 `bson:"accepted"`
+		Solvers  int64 `bson:"solvers"`
+		Attempts int64 `bson:"attempts"`
+	}
+	if err := cursor.All(context.Background(), &counts); err != nil {
+		return nil, err
+	}
+
+	stats := models.QuestionStats{}
+	if len(counts) == 0 {
+		return &stats, nil
+	}
+	stats.TotalSubmissions = counts[0].Total
+	stats.AcceptedSubmissions = counts[0].Accepted
+	stats.Solvers = counts[0].Solvers
+	if stats.TotalSubmissions > 0 {
+		stats.AcceptanceRate = float64(stats.AcceptedSubmissions) / float64(stats.TotalSubmissions)
+	}
+	if stats.Solvers > 0 {
+		stats.AverageAttempts = float64(counts[0].Attempts) / float64(stats.Solvers)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"testing"
+)
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		difficulty string
+		level      int
+		err        error
+	}{
+		{"Easy", models.LevelEasy, nil},
+		{"medium", models.LevelMedium, nil},
+		{"HARD", models.LevelHard, nil},
+		{"", 0, ErrInvalidDifficulty},
+		{"Extreme", 0, ErrInvalidDifficulty},
+		{"2", 0, ErrInvalidDifficulty},
+	}
+	for _, test := range tests {
+		t.Run(test.difficulty, func(t *testing.T) {
+			level, err := ParseDifficulty(test.difficulty)
+			if level != test.level || err != test.err {
+				t.Errorf("ParseDifficulty(%q) = %d, %v, want %d, %v", test.difficulty, level, err, test.level, test.err)
+			}
+		})
+	}
+}
+
+func TestResolveDifficulty(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      int
+		difficulty string
+		want       int
+		err        error
+	}{
+		{"level only", models.LevelHard, "", models.LevelHard, nil},
+		{"difficulty only", 0, "Medium", models.LevelMedium, nil},
+		{"matching level and difficulty", models.LevelEasy, "easy", models.LevelEasy, nil},
+		{"conflicting level and difficulty", models.LevelEasy, "Hard", models.LevelEasy, ErrInvalidDifficulty},
+		{"unknown difficulty", 0, "Extreme", 0, ErrInvalidDifficulty},
+		{"neither", 0, "", 0, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			question := models.Question{Level: test.level, Difficulty: test.difficulty}
+			err := resolveDifficulty(&question)
+			if question.Level != test.want || err != test.err {
+				t.Errorf("resolveDifficulty() level = %d, error = %v, want %d, %v", question.Level, err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestLegacyLevel(t *testing.T) {
+	tests := map[int]int{-1: models.LevelEasy, 0: models.LevelEasy, 1: models.LevelEasy, 2: models.LevelMedium, 3: models.LevelHard, 4: models.LevelHard, 10: models.LevelHard}
+	for level, want := range tests {
+		if got := legacyLevel(level); got != want {
+			t.Errorf("legacyLevel(%d) = %d, want %d", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var submissionCollection *mongo.Collection
+
+// metricFields maps the metric names accepted by the API to the submission fields that store them.
+var metricFields = map[string]string{
+	"runtime": "runtimeMs",
+	"memory":  "memoryKb",
+}
+
+// initSubmissions sets up the submissions collection and the indexes used by the percentile queries and the per-user lookups.
+func initSubmissions() {
+	submissionCollection = database.Collection("submissions")
+	_, err := submissionCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "questionId", Value: 1}, {Key: "language", Value: 1}, {Key: "accepted", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "questionId", Value: 1}, {Key: "accepted", Value: 1}}},
+		{Keys: bson.D{{Key: "questionId", Value: 1}, {Key: "createdAt", Value: 1}}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CreateSubmission stores the results of a user's run against a version of a question. The submission is accepted when every test passed,
+// its runtime is the sum of the test runtimes and its memory is the peak memory of all tests.
+func CreateSubmission(userID primitive.ObjectID, questionId string, questionVersion int, language, code string, results []models.TestResult) (*models.Submission, error) {
+	questionID, err := primitive.ObjectIDFromHex(questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := models.Submission{
+		QuestionID:      questionID,
+		QuestionVersion: questionVersion,
+		UserID:          userID,
+		Language:        language,
+		Code:            code,
+		Accepted:        len(results) > 0,
+		Results:         results,
+		CreatedAt:       time.Now(),
+	}
+	for _, result := range results {
+		if !result.Passed {
+			submission.Accepted = false
+		}
+		submission.RuntimeMs += result.RuntimeMs
+		if result.MemoryKb > submission.MemoryKb {
+			submission.MemoryKb = result.MemoryKb
+		}
+	}
+
+	result, err := submissionCollection.InsertOne(context.Background(), submission)
+	if err != nil {
+		return nil, err
+	}
+	submission.ID = result.InsertedID.(primitive.ObjectID)
+	return &submission, nil
+}
+
+// GetSubmissionPercentile compares an accepted submission with the prior accepted submissions for the same question and language.
+// It returns the percentage of those submissions that used more runtime and more memory than the given one.
+func GetSubmissionPercentile(submission *models.Submission) (*models.Percentile, error) {
+	filter := bson.M{
+		"questionId": submission.QuestionID,
+		"language":   submission.Language,
+		"accepted":   true,
+		"_id":        bson.M{"$ne": submission.ID},
+	}
+
+	total, err := submissionCollection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return &models.Percentile{Runtime: percentile(0, 0), Memory: percentile(0, 0)}, nil
+	}
+
+	filter["runtimeMs"] = bson.M{"$gt": submission.RuntimeMs}
+	slower, err := submissionCollection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	delete(filter, "runtimeMs")
+
+	filter["memoryKb"] = bson.M{"$gt": submission.MemoryKb}
+	heavier, err := submissionCollection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Percentile{
+		Runtime: percentile(slower, total),
+		Memory:  percentile(heavier, total),
+		Total:   total,
+	}, nil
+}
+
+// percentile returns the percentage of the total submissions beaten by a submission. The first accepted submission
+// beats them all.
+func percentile(beaten, total int64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(beaten) * 100 / float64(total)
+}
+
+// GetDistribution builds a histogram of the given metric ("runtime" or "memory") over the accepted submissions
+// for a question in a language. It returns up to the requested number of buckets.
+func GetDistribution(questionId, language, metric string, buckets int) (*models.Distribution, error) {
+	questionID, err := primitive.ObjectIDFromHex(questionId)
+	if err != nil {
+		return nil, err
+	}
+	field, ok := metricFields[metric]
+	if !ok {
+		return nil, ErrUnsupportedMetric
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"questionId": questionID, "language": language, "accepted": true}}},
+		{{Key: "$bucketAuto", Value: bson.M{"groupBy": "$" + field, "buckets": buckets}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "min": "$_id.min", "max": "$_id.max", "count": 1}}},
+	}
+	cursor, err := submissionCollection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	distribution := models.Distribution{Metric: metric, Buckets: []models.HistogramBucket{}}
+	if err := cursor.All(context.Background(), &distribution.Buckets); err != nil {
+		return nil, err
+	}
+	return &distribution, nil
+}
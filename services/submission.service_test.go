@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		beaten int64
+		total  int64
+		want   float64
+	}{
+		{"first submission", 0, 0, 100},
+		{"beats none", 0, 4, 0},
+		{"beats some", 1, 4, 25},
+		{"beats all", 3, 3, 100},
+		{"fraction", 1, 3, 100.0 / 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := percentile(test.beaten, test.total); got != test.want {
+				t.Errorf("percentile(%d, %d) = %v, want %v", test.beaten, test.total, got, test.want)
+			}
+		})
+	}
+}
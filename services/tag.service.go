@@ -0,0 +1,159 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var tagCollection *mongo.Collection
+
+// initTags sets up the tags collection, whose unique index on the normalized name prevents near-duplicate tags.
+func initTags() {
+	tagCollection = database.Collection("tags")
+	_, err := tagCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NormalizeTag folds the spelling variants of a tag name ("Dynamic-Programming", " dynamic  programming") to a single form.
+func NormalizeTag(name string) string {
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CreateTag adds a tag to the taxonomy. The category is either "topic" (the default) or "company".
+func CreateTag(name, category string) (*models.Tag, error) {
+	name = NormalizeTag(name)
+	if category == "" {
+		category = models.TagCategoryTopic
+	}
+	if name == "" || (category != models.TagCategoryTopic && category != models.TagCategoryCompany) {
+		return nil, ErrInvalidTag
+	}
+
+	tag := models.Tag{Name: name, Category: category}
+	result, err := tagCollection.InsertOne(context.Background(), tag)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrTagExists
+		}
+		return nil, err
+	}
+	tag.ID = result.InsertedID.(primitive.ObjectID)
+	return &tag, nil
+}
+
+// DeleteTag removes a tag from the taxonomy. Tags still used by questions can't be removed: the questions must first be
+// edited, so that the removal is recorded in their versions.
+func DeleteTag(name string) error {
+	name = NormalizeTag(name)
+	used, err := questionCollection.CountDocuments(context.Background(), bson.M{"tags": name}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrTagInUse
+	}
+
+	result, err := tagCollection.DeleteOne(context.Background(), bson.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// GetAllTags returns the taxonomy, optionally restricted to a category, with the number of questions using each tag.
+func GetAllTags(category string) ([]models.Tag, error) {
+	filter := bson.M{}
+	if category != "" {
+		filter["category"] = category
+	}
+	cursor, err := tagCollection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	tags := []models.Tag{}
+	if err := cursor.All(context.Background(), &tags); err != nil {
+		return nil, err
+	}
+
+	cursor, err = questionCollection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(context.Background(), &counts); err != nil {
+		return nil, err
+	}
+
+	countByName := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		countByName[count.Name] = count.Count
+	}
+	for i := range tags {
+		tags[i].QuestionCount = countByName[tags[i].Name]
+	}
+	return tags, nil
+}
+
+// normalizeQuestionTags normalizes the tags of a question and checks that each of them is part of the taxonomy.
+func normalizeQuestionTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		name := NormalizeTag(tag)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+
+	var known []string
+	names, err := tagCollection.Distinct(context.Background(), "name", bson.M{"name": bson.M{"$in": normalized}})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if s, ok := name.(string); ok {
+			known = append(known, s)
+		}
+	}
+
+	var unknown []string
+	for _, name := range normalized {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTag, strings.Join(unknown, ", "))
+	}
+	return normalized, nil
+}
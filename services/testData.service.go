@@ -0,0 +1,303 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testDataGracePeriod is how long test data files no question version references are kept before being collected,
+// so that the files of an update still in progress are not removed.
+const testDataGracePeriod = time.Hour
+
+var testDataBucket *gridfs.Bucket
+
+// testInlineLimit is the size in bytes above which test inputs and expected outputs are stored in GridFS,
+// which keeps question documents under the 16MB document limit of Mongo.
+var testInlineLimit int
+
+// TestArchiveLimit is the maximum size in bytes of an uploaded test archive, compressed or not.
+var TestArchiveLimit int64
+
+// initTestData sets up the GridFS bucket holding large test data and reads its limits from
+// TEST_INLINE_LIMIT_KB (default 64) and TEST_ARCHIVE_MAX_MB (default 64).
+func initTestData() {
+	var err error
+	testDataBucket, err = gridfs.NewBucket(database, options.GridFSBucket().SetName("testData"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	testInlineLimit = envInt("TEST_INLINE_LIMIT_KB", 64) * 1024
+	TestArchiveLimit = int64(envInt("TEST_ARCHIVE_MAX_MB", 64)) * 1024 * 1024
+}
+
+// storeLargeTests moves the inputs and expected outputs larger than testInlineLimit to GridFS. A test that sets an
+// inline value replaces the file it referenced, while a test with an empty inline value keeps its file only when a
+// version of the question already referenced it, so that the file IDs sent by clients can't expose the test data of
+// other questions. questionID is the nil ObjectID for a new question, whose tests can't reference any file yet.
+// Files are only removed by collectTestData once no version of any question references them.
+func storeLargeTests(questionID primitive.ObjectID, tests []models.Test) ([]models.Test, error) {
+	var files map[primitive.ObjectID]bool
+	stored := make([]models.Test, len(tests))
+	for i, test := range tests {
+		var err error
+		if files == nil && !questionID.IsZero() && (test.InputFile != nil || test.ExpectedOutputFile != nil) {
+			if files, err = questionTestFiles(questionID); err != nil {
+				return nil, err
+			}
+		}
+		if test.Input, test.InputFile, err = storeTestData(test.Input, test.InputFile, files); err != nil {
+			return nil, err
+		}
+		if test.ExpectedOutput, test.ExpectedOutputFile, err = storeTestData(test.ExpectedOutput, test.ExpectedOutputFile, files); err != nil {
+			return nil, err
+		}
+		stored[i] = test
+	}
+	return stored, nil
+}
+
+// storeTestData returns how one test value is stored: inline, or as a reference to a GridFS file among the files
+// the question may reference.
+func storeTestData(data string, file *primitive.ObjectID, files map[primitive.ObjectID]bool) (string, *primitive.ObjectID, error) {
+	if data == "" {
+		if file != nil && files[*file] {
+			return "", file, nil
+		}
+		return "", nil, nil
+	}
+	if len(data) <= testInlineLimit {
+		return data, nil, nil
+	}
+	id, err := testDataBucket.UploadFromStream("test", strings.NewReader(data))
+	if err != nil {
+		return "", nil, err
+	}
+	return "", &id, nil
+}
+
+// questionTestFiles returns the GridFS files referenced by the tests of any version of a question.
+func questionTestFiles(questionID primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	cursor, err := questionVersionCollection.Find(context.Background(), bson.M{"questionId": questionID},
+		options.Find().SetProjection(bson.M{"question.tests.inputFile": 1, "question.tests.expectedOutputFile": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.QuestionVersion
+	if err := cursor.All(context.Background(), &versions); err != nil {
+		return nil, err
+	}
+
+	files := map[primitive.ObjectID]bool{}
+	for _, version := range versions {
+		for _, test := range version.Question.Tests {
+			for _, file := range []*primitive.ObjectID{test.InputFile, test.ExpectedOutputFile} {
+				if file != nil {
+					files[*file] = true
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// collectTestData removes the test data files that no version of any question references, such as the files stored by
+// an update that then failed. Versions are never removed, even when their question is purged from the trash, so that
+// past submissions stay reproducible; the files they reference are kept with them.
+func collectTestData() error {
+	referenced := bson.A{}
+	for _, field := range []string{"question.tests.inputFile", "question.tests.expectedOutputFile"} {
+		files, err := questionVersionCollection.Distinct(context.Background(), field, bson.M{})
+		if err != nil {
+			return err
+		}
+		referenced = append(referenced, files...)
+	}
+
+	cursor, err := testDataBucket.Find(bson.M{
+		"_id":        bson.M{"$nin": referenced},
+		"uploadDate": bson.M{"$lt": time.Now().Add(-testDataGracePeriod)},
+	})
+	if err != nil {
+		return err
+	}
+	var files []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(context.Background(), &files); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := testDataBucket.Delete(file.ID); err != nil && err != gridfs.ErrFileNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTest returns a test with the inputs and expected outputs stored in GridFS read back inline.
+func loadTest(test models.Test) (models.Test, error) {
+	var err error
+	if test.InputFile != nil {
+		if test.Input, err = loadTestData(*test.InputFile); err != nil {
+			return test, err
+		}
+		test.InputFile = nil
+	}
+	if test.ExpectedOutputFile != nil {
+		if test.ExpectedOutput, err = loadTestData(*test.ExpectedOutputFile); err != nil {
+			return test, err
+		}
+		test.ExpectedOutputFile = nil
+	}
+	return test, nil
+}
+
+// previewTestData shortens test data larger than testInlineLimit so that test results and submissions stay small.
+func previewTestData(data string) string {
+	if len(data) <= testInlineLimit {
+		return data
+	}
+	// Cut before the character straddling the limit rather than in the middle of it.
+	end := testInlineLimit
+	for end > 0 && !utf8.RuneStart(data[end]) {
+		end--
+	}
+	return data[:end] + "... (truncated)"
+}
+
+func loadTestData(id primitive.ObjectID) (string, error) {
+	var data bytes.Buffer
+	if _, err := testDataBucket.DownloadToStream(id, &data); err != nil {
+		return "", err
+	}
+	return data.String(), nil
+}
+
+// UploadTests adds the test cases of a zip archive of N.in and N.out files to a question, ordered by N, after its
+// existing tests or in place of them when replace is set.
+func UploadTests(id string, editorID primitive.ObjectID, expectedVersion int, archive []byte, replace bool) (*models.Question, error) {
+	tests, err := parseTestArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	return modifyQuestion(id, editorID, expectedVersion, func(question *models.Question) error {
+		if replace {
+			question.Tests = nil
+		}
+		question.Tests = append(question.Tests, tests...)
+		return nil
+	})
+}
+
+// parseTestArchive reads the test cases of a zip archive, in which every N.in file holds the input of a test and the
+// matching N.out file its expected output. Directories are ignored, as are hidden files and files with other extensions.
+func parseTestArchive(archive []byte) ([]models.Test, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTestArchive, err)
+	}
+
+	inputs := map[string]string{}
+	outputs := map[string]string{}
+	remaining := TestArchiveLimit
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		name := path.Base(file.Name)
+		ext := path.Ext(name)
+		if strings.HasPrefix(name, ".") || (ext != ".in" && ext != ".out") {
+			continue
+		}
+
+		content, err := readArchiveFile(file, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+
+		key := strings.TrimSuffix(name, ext)
+		target := inputs
+		if ext == ".out" {
+			target = outputs
+		}
+		if _, ok := target[key]; ok {
+			return nil, fmt.Errorf("%w: %s appears more than once", ErrInvalidTestArchive, name)
+		}
+		target[key] = content
+	}
+
+	var keys []string
+	for key := range inputs {
+		if _, ok := outputs[key]; !ok {
+			return nil, fmt.Errorf("%w: %s.in has no matching %s.out", ErrInvalidTestArchive, key, key)
+		}
+		keys = append(keys, key)
+	}
+	for key := range outputs {
+		if _, ok := inputs[key]; !ok {
+			return nil, fmt.Errorf("%w: %s.out has no matching %s.in", ErrInvalidTestArchive, key, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no test found", ErrInvalidTestArchive)
+	}
+
+	slices.SortFunc(keys, compareTestNames)
+	tests := make([]models.Test, len(keys))
+	for i, key := range keys {
+		tests[i] = models.Test{Input: inputs[key], ExpectedOutput: outputs[key]}
+	}
+	return tests, nil
+}
+
+// readArchiveFile reads a file of a test archive, failing when it would exceed the remaining uncompressed size.
+func readArchiveFile(file *zip.File, remaining int64) (string, error) {
+	content, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTestArchive, err)
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(io.LimitReader(content, remaining+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTestArchive, err)
+	}
+	if int64(len(data)) > remaining {
+		return "", ErrTestArchiveTooLarge
+	}
+	return string(data), nil
+}
+
+// compareTestNames orders numbered tests numerically (2 before 10), and other names alphabetically after them.
+func compareTestNames(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return na - nb
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPreviewTestData(t *testing.T) {
+	testInlineLimit = 8
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"short", "1 2 3", "1 2 3"},
+		{"at limit", "12345678", "12345678"},
+		{"over limit", "123456789", "12345678... (truncated)"},
+		{"multi-byte at limit", "123456é", "123456é"},
+		{"multi-byte across limit", "1234567é9", "1234567... (truncated)"},
+		{"multi-byte before limit", "123456é89", "123456é... (truncated)"},
+		{"four-byte character", "12345😀89", "12345... (truncated)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := previewTestData(test.data)
+			if got != test.want {
+				t.Errorf("previewTestData(%q) = %q, want %q", test.data, got, test.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("previewTestData(%q) = %q is not valid UTF-8", test.data, got)
+			}
+		})
+	}
+}
+
+func TestParseTestArchive(t *testing.T) {
+	TestArchiveLimit = 64
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []models.Test
+		err   error
+	}{
+		{
+			name:  "numeric order",
+			files: map[string]string{"10.in": "j", "10.out": "J", "2.in": "b", "2.out": "B", "1.in": "a", "1.out": "A"},
+			want:  []models.Test{{Input: "a", ExpectedOutput: "A"}, {Input: "b", ExpectedOutput: "B"}, {Input: "j", ExpectedOutput: "J"}},
+		},
+		{
+			name:  "names after numbers",
+			files: map[string]string{"edge.in": "e", "edge.out": "E", "1.in": "a", "1.out": "A"},
+			want:  []models.Test{{Input: "a", ExpectedOutput: "A"}, {Input: "e", ExpectedOutput: "E"}},
+		},
+		{
+			name: "ignored files",
+			files: map[string]string{
+				"tests/1.in": "a", "tests/1.out": "A", "README.md": "tests", "tests/.2.in": "x",
+				"__MACOSX/tests/._1.in": "x", "tests/": "",
+			},
+			want: []models.Test{{Input: "a", ExpectedOutput: "A"}},
+		},
+		{name: "missing output", files: map[string]string{"1.in": "a", "1.out": "A", "2.in": "b"}, err: ErrInvalidTestArchive},
+		{name: "missing input", files: map[string]string{"1.out": "A"}, err: ErrInvalidTestArchive},
+		{name: "duplicate", files: map[string]string{"a/1.in": "a", "b/1.in": "b", "1.out": "A"}, err: ErrInvalidTestArchive},
+		{name: "empty", files: map[string]string{"README.md": "tests"}, err: ErrInvalidTestArchive},
+		{name: "too large", files: map[string]string{"1.in": strings.Repeat("a", 40), "1.out": strings.Repeat("A", 40)}, err: ErrTestArchiveTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTestArchive(zipArchive(t, test.files))
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("parseTestArchive() error = %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTestArchive() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTestArchive() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := parseTestArchive([]byte("not a zip")); !errors.Is(err, ErrInvalidTestArchive) {
+		t.Errorf("parseTestArchive() of a non-zip error = %v, want ErrInvalidTestArchive", err)
+	}
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive.Bytes()
+}
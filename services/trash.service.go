@@ -0,0 +1,116 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// trashPurgeInterval is how often deleted questions past the retention window are purged.
+const trashPurgeInterval = time.Hour
+
+var trashRetention time.Duration
+
+// initTrash reads the number of days deleted questions are kept from QUESTION_RETENTION_DAYS and starts purging older ones,
+// along with the test data files no longer referenced by any question version.
+func initTrash() {
+	trashRetention = time.Duration(envInt("QUESTION_RETENTION_DAYS", 30)) * 24 * time.Hour
+	go func() {
+		for {
+			if err := purgeTrash(); err != nil {
+				log.Printf("failed to purge deleted questions: %v", err)
+			}
+			if err := collectTestData(); err != nil {
+				log.Printf("failed to collect unused test data: %v", err)
+			}
+			time.Sleep(trashPurgeInterval)
+		}
+	}()
+}
+
+// purgeTrash permanently removes the questions deleted before the retention window, along with their attachments.
+// Their versions, and the test data files the versions reference, are kept so that past submissions stay reproducible.
+func purgeTrash() error {
+	filter := bson.M{"deletedAt": bson.M{"$lt": purgeCutoff(time.Now())}}
+	cursor, err := questionCollection.Find(context.Background(), filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var questions []models.Question
+	if err := cursor.All(context.Background(), &questions); err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+	if err := deleteQuestionAttachments(ids); err != nil {
+		return err
+	}
+	_, err = questionCollection.DeleteMany(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// purgeCutoff returns the time before which deleted questions are purged.
+func purgeCutoff(now time.Time) time.Time {
+	return now.Add(-trashRetention)
+}
+
+// trashFilter selects the questions in the trash, only those of an author when authorID is set.
+func trashFilter(authorID primitive.ObjectID) bson.M {
+	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+	if !authorID.IsZero() {
+		filter["authorId"] = authorID
+	}
+	return filter
+}
+
+// GetDeletedQuestions lists the questions in the trash, most recently deleted first. When authorID is set,
+// only the questions of that author are listed.
+func GetDeletedQuestions(authorID primitive.ObjectID) ([]models.QuestionSummary, error) {
+	cursor, err := questionCollection.Find(context.Background(), trashFilter(authorID), options.Find().
+		SetSort(bson.M{"deletedAt": -1}).
+		SetProjection(bson.M{"title": 1, "level": 1, "tags": 1, "languages": 1, "authorId": 1, "deletedAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	questions := []models.QuestionSummary{}
+	if err := cursor.All(context.Background(), &questions); err != nil {
+		return nil, err
+	}
+	for i := range questions {
+		questions[i].Difficulty = models.Difficulties[questions[i].Level]
+	}
+	return questions, nil
+}
+
+// RestoreQuestion takes a question out of the trash. It returns ErrQuestionNotFound when the question is not in the trash.
+func RestoreQuestion(id string) (*models.Question, error) {
+	questionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrQuestionNotFound
+	}
+
+	var question models.Question
+	err = questionCollection.FindOneAndUpdate(context.Background(),
+		bson.M{"_id": questionID, "deletedAt": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&question)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return &question, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPurgeCutoff(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		days int
+		want time.Time
+	}{
+		{30, time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)},
+		{1, time.Date(2024, time.March, 30, 12, 0, 0, 0, time.UTC)},
+		{0, now},
+	}
+	for _, test := range tests {
+		trashRetention = time.Duration(test.days) * 24 * time.Hour
+		if got := purgeCutoff(now); !got.Equal(test.want) {
+			t.Errorf("purgeCutoff() with %d days = %v, want %v", test.days, got, test.want)
+		}
+	}
+}
+
+func TestTrashFilter(t *testing.T) {
+	author := primitive.NewObjectID()
+	tests := []struct {
+		name     string
+		authorID primitive.ObjectID
+		want     bson.M
+	}{
+		{"every author", primitive.NilObjectID, bson.M{"deletedAt": bson.M{"$exists": true}}},
+		{"one author", author, bson.M{"deletedAt": bson.M{"$exists": true}, "authorId": author}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := trashFilter(test.authorID); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("trashFilter() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanManageQuestion(t *testing.T) {
+	author := models.User{ID: primitive.NewObjectID(), Role: models.RoleAuthor}
+	deletedAt := time.Now()
+	question := models.Question{AuthorID: author.ID, DeletedAt: &deletedAt}
+	tests := []struct {
+		name string
+		user models.User
+		want bool
+	}{
+		{"admin", models.User{ID: primitive.NewObjectID(), Role: models.RoleAdmin}, true},
+		{"its author", author, true},
+		{"another author", models.User{ID: primitive.NewObjectID(), Role: models.RoleAuthor}, false},
+		{"solver", models.User{ID: primitive.NewObjectID(), Role: models.RoleSolver}, false},
+		{"demoted author", models.User{ID: author.ID, Role: models.RoleSolver}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CanManageQuestion(&test.user, &question); got != test.want {
+				t.Errorf("CanManageQuestion() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tokenTTL = 24 * time.Hour
+
+var userCollection *mongo.Collection
+var authSecret []byte
+
+// tokenClaims is the signed payload of a session token.
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// initUsers sets up the users collection with a unique username index, loads the AUTH_SECRET used to sign session tokens
+// and creates the admin account named by ADMIN_USERNAME and ADMIN_PASSWORD when they are set.
+func initUsers() {
+	authSecret = []byte(os.Getenv("AUTH_SECRET"))
+	if len(authSecret) < 32 {
+		log.Fatal("AUTH_SECRET must be set to at least 32 characters")
+	}
+
+	userCollection = database.Collection("users")
+	_, err := userCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if username := os.Getenv("ADMIN_USERNAME"); username != "" {
+		if err := bootstrapAdmin(username, os.Getenv("ADMIN_PASSWORD")); err != nil {
+			log.Fatalf("Failed to create the admin account: %v", err)
+		}
+	}
+}
+
+// bootstrapAdmin makes sure the deployment has the given admin account, so that roles can be granted to the other users.
+// A new account is created with the given password. An existing account is kept when it is already an admin, and only
+// promoted when its password is the given one, so that an account someone registered under the admin username before
+// the first start can't become the admin.
+func bootstrapAdmin(username, password string) error {
+	var user models.User
+	err := userCollection.FindOne(context.Background(), bson.M{"username": strings.TrimSpace(username)}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		created, err := RegisterUser(username, password)
+		if err == ErrUsernameTaken {
+			// Another server replica created the account in the meantime.
+			return bootstrapAdmin(username, password)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = SetUserRole(created.ID.Hex(), models.RoleAdmin)
+		return err
+	}
+	if err != nil || user.Role == models.RoleAdmin {
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return fmt.Errorf("an account named %s already exists with another password, choose another ADMIN_USERNAME", user.Username)
+	}
+	_, err = SetUserRole(user.ID.Hex(), models.RoleAdmin)
+	return err
+}
+
+// RegisterUser creates a new user with a bcrypt hash of the given password. It returns the created user and any errors encountered.
+func RegisterUser(username, password string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || len(password) < 8 {
+		return nil, ErrInvalidUser
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         models.RoleSolver,
+		CreatedAt:    time.Now(),
+	}
+	result, err := userCollection.InsertOne(context.Background(), user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+	return &user, nil
+}
+
+// Login checks the given credentials and returns a signed session token for the user.
+func Login(username, password string) (string, *models.User, error) {
+	var user models.User
+	err := userCollection.FindOne(context.Background(), bson.M{"username": strings.TrimSpace(username)}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := signToken(tokenClaims{Subject: user.ID.Hex(), ExpiresAt: time.Now().Add(tokenTTL).Unix()})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &user, nil
+}
+
+// GetUserByID retrieves a user by its ID. It returns the user object and any errors encountered.
+func GetUserByID(id string) (*models.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role == "" {
+		user.Role = models.RoleSolver
+	}
+	return &user, nil
+}
+
+// SetUserRole changes the role of a user. It returns the updated user and any errors encountered.
+func SetUserRole(id, role string) (*models.User, error) {
+	if role != models.RoleAdmin && role != models.RoleAuthor && role != models.RoleSolver {
+		return nil, ErrInvalidRole
+	}
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var user models.User
+	err = userCollection.FindOneAndUpdate(context.Background(), bson.M{"_id": userID}, bson.M{"$set": bson.M{"role": role}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Authenticate verifies a session token and returns the user it was issued to.
+func Authenticate(token string) (*models.User, error) {
+	claims, err := verifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := GetUserByID(claims.Subject)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// signToken encodes the claims and appends an HMAC-SHA256 signature, producing "<payload>.<signature>".
+func signToken(claims tokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(tokenSignature(encoded)), nil
+}
+
+// verifyToken checks the signature and expiry of a token created by signToken and returns its claims.
+func verifyToken(token string) (*tokenClaims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, tokenSignature(encoded)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+func tokenSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, authSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
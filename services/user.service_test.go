@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyToken(t *testing.T) {
+	authSecret = []byte(strings.Repeat("s", 32))
+	sign := func(claims tokenClaims) string {
+		token, err := signToken(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := sign(tokenClaims{Subject: "user", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	payload, signature, _ := strings.Cut(valid, ".")
+	otherPayload, _, _ := strings.Cut(sign(tokenClaims{Subject: "admin", ExpiresAt: time.Now().Add(time.Hour).Unix()}), ".")
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", valid, true},
+		{"expired", sign(tokenClaims{Subject: "user", ExpiresAt: time.Now().Add(-time.Minute).Unix()}), false},
+		{"tampered payload", otherPayload + "." + signature, false},
+		{"tampered signature", payload + "." + signature[1:] + "A", false},
+		{"no signature", payload, false},
+		{"bad encoding", payload + ".!!!", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := verifyToken(test.token)
+			if !test.valid {
+				if err != ErrInvalidToken {
+					t.Fatalf("verifyToken() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyToken() error = %v", err)
+			}
+			if claims.Subject != "user" {
+				t.Errorf("verifyToken() subject = %q, want %q", claims.Subject, "user")
+			}
+		})
+	}
+
+	authSecret = []byte(strings.Repeat("o", 32))
+	if _, err := verifyToken(valid); err != ErrInvalidToken {
+		t.Errorf("verifyToken() with another secret error = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"LeetCode-server/models"
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var questionVersionCollection *mongo.Collection
+
+// initVersions sets up the question versions collection and gives a first version to questions created before versioning existed.
+func initVersions() {
+	questionVersionCollection = database.Collection("questionVersions")
+	_, err := questionVersionCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "questionId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cursor, err := questionCollection.Find(context.Background(), bson.M{"version": bson.M{"$exists": false}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cursor.Close(context.Background())
+	for cursor.Next(context.Background()) {
+		var question models.Question
+		if err := cursor.Decode(&question); err != nil {
+			log.Fatal(err)
+		}
+		question.Version = 1
+		if err := saveQuestionVersion(question, question.AuthorID); err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Fatal(err)
+		}
+		_, err := questionCollection.UpdateOne(context.Background(), bson.M{"_id": question.ID}, bson.M{"$set": bson.M{"version": 1}})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// saveQuestionVersion stores a snapshot of the question as it is at its current version.
+func saveQuestionVersion(question models.Question, editorID primitive.ObjectID) error {
+	_, err := questionVersionCollection.InsertOne(context.Background(), models.QuestionVersion{
+		QuestionID: question.ID,
+		Version:    question.Version,
+		EditorID:   editorID,
+		CreatedAt:  time.Now(),
+		Question:   question,
+	})
+	return err
+}
+
+// GetQuestionVersion retrieves the snapshot of a question at the given version for a user. The versions of a question in
+// the trash are only found by the users who can manage it.
+func GetQuestionVersion(id string, version int, user *models.User) (*models.QuestionVersion, error) {
+	if err := checkVersionsVisible(id, user); err != nil {
+		return nil, err
+	}
+	return getQuestionVersion(id, version)
+}
+
+// checkVersionsVisible returns ErrQuestionNotFound when a user may not read the versions of a question: when the question
+// was purged, or when it is in the trash and the user can't manage it.
+func checkVersionsVisible(id string, user *models.User) error {
+	question, err := findQuestion(id, bson.M{})
+	if err != nil {
+		return err
+	}
+	if question.DeletedAt != nil && !CanManageQuestion(user, question) {
+		return ErrQuestionNotFound
+	}
+	return nil
+}
+
+// getQuestionVersion retrieves the snapshot of a question at the given version.
+func getQuestionVersion(id string, version int) (*models.QuestionVersion, error) {
+	questionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrQuestionNotFound
+	}
+
+	var snapshot models.QuestionVersion
+	err = questionVersionCollection.FindOne(context.Background(), bson.M{"questionId": questionID, "version": version}).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetQuestionHistory lists the versions of a question, newest first, with the changes each version made to the previous one.
+// Changes to the hints and editorial are left out for the users who can't manage the question, and the history of a
+// question in the trash is only found by the users who can manage it.
+func GetQuestionHistory(id string, user *models.User) ([]models.QuestionVersionSummary, error) {
+	if err := checkVersionsVisible(id, user); err != nil {
+		return nil, err
+	}
+	questionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrQuestionNotFound
+	}
+
+	cursor, err := questionVersionCollection.Find(context.Background(), bson.M{"questionId": questionID},
+		options.Find().SetSort(bson.M{"version": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.QuestionVersion
+	if err := cursor.All(context.Background(), &versions); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrQuestionNotFound
+	}
+
+	redact := !CanManageQuestion(user, &versions[len(versions)-1].Question)
+	history := make([]models.QuestionVersionSummary, len(versions))
+	var previous models.Question
+	for i, version := range versions {
+		if redact {
+			version.Question.Hints = nil
+			version.Question.Editorial = nil
+		}
+		history[len(versions)-1-i] = models.QuestionVersionSummary{
+			Version:   version.Version,
+			EditorID:  version.EditorID,
+			CreatedAt: version.CreatedAt,
+			Changes:   diffQuestions(previous, version.Question),
+		}
+		previous = version.Question
+	}
+	return history, nil
+}
+
+// RollbackQuestion restores the content of a previous version. The history is kept intact:
+// the restored content is saved as a new version. expectedVersion works as in UpdateQuestion.
+func RollbackQuestion(id string, version int, editorID primitive.ObjectID, expectedVersion int) (*models.Question, error) {
+	snapshot, err := getQuestionVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+	// Versions saved before the levels were restricted may have a legacy level.
+	snapshot.Question.Level = legacyLevel(snapshot.Question.Level)
+	return UpdateQuestion(id, editorID, expectedVersion, snapshot.Question)
+}
+
+// diffQuestions lists the fields that differ between two versions of a question. Tests are compared one by one.
+func diffQuestions(before, after models.Question) []models.FieldChange {
+	changes := []models.FieldChange{}
+	compare := func(field string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) && !(isEmptySlice(a) && isEmptySlice(b)) {
+			changes = append(changes, models.FieldChange{Field: field, Before: a, After: b})
+		}
+	}
+
+	compare("title", before.Title, after.Title)
+	compare("description", before.Description, after.Description)
+	compare("level", before.Level, after.Level)
+	compare("inputTypes", before.InputTypes, after.InputTypes)
+	compare("outputType", before.OutputType, after.OutputType)
+	compare("tags", before.Tags, after.Tags)
+	compare("languages", before.Languages, after.Languages)
+	compare("examples", before.Examples, after.Examples)
+	compare("hints", before.Hints, after.Hints)
+	compare("editorial", before.Editorial, after.Editorial)
+	for i := 0; i < max(len(before.Tests), len(after.Tests)); i++ {
+		var a, b interface{}
+		if i < len(before.Tests) {
+			a = before.Tests[i]
+		}
+		if i < len(after.Tests) {
+			b = after.Tests[i]
+		}
+		compare(fmt.Sprintf("tests[%d]", i), a, b)
+	}
+	return changes
+}
+
+// isEmptySlice reports whether a value is a nil or empty slice, which are stored the same way.
+func isEmptySlice(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Slice && v.Len() == 0
+}